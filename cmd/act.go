@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/action"
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/log"
+)
+
+var actCmd = &cobra.Command{
+	Use:   "act [id]",
+	Short: "Open, run or fetch a clipboard history item based on its content",
+	Long: `Offer actions for a clipboard history item based on its detected content type (default: the newest item)
+  url      open in the browser (xdg-open), fetch image files into the current directory
+  path     edit the file in $VISUAL/$EDITOR, open it with xdg-open (file manager for directories), cd into a directory
+  command  run it after confirmation
+  The 'actions' section of the config adds actions per content type (command templates with .Text, .Path, .ID
+  and the quote function); they ask for confirmation before running.
+  cd can only change the calling shell: with --shell its command line is printed for the shell integration to evaluate,
+  the output of the other actions goes to stderr then.`,
+	Example: `  homie act 42
+  homie act --action open
+  eval "$(homie act --shell --action cd)"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		name, err := flags.GetString("action")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'action' flag: %v", err)
+		}
+		yes, err := flags.GetBool("yes")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'yes' flag: %v", err)
+		}
+		shell, err := flags.GetBool("shell")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'shell' flag: %v", err)
+		}
+		list, err := flags.GetBool("list")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'list' flag: %v", err)
+		}
+
+		id := 0
+		if len(args) > 0 {
+			if id, err = strconv.Atoi(args[0]); err != nil {
+				log.Logger().Fatalf("invalid id %q: %v", args[0], err)
+			}
+		}
+		item, err := fetchHistoryItem(1, id, len(args) > 0)
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		catalog, err := action.NewCatalog(config.Actions())
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+
+		d := action.NewData(item)
+		actions := catalog.For(d)
+		if list {
+			for _, a := range actions {
+				fmt.Printf("%s\t%s\n", a.Name, a.Description)
+			}
+			return
+		}
+
+		var a action.Action
+		if name != "" {
+			a, err = catalog.Lookup(d, name)
+		} else {
+			var ok bool
+			a, ok, err = chooseContentAction(actions, d)
+			if !ok && err == nil {
+				return
+			}
+		}
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		if err = runContentAction(a, d, shell, yes); err != nil {
+			log.Logger().Fatal(err)
+		}
+	},
+}
+
+// chooseContentAction shows the actions for d in a menu; ok is false when it was dismissed.
+func chooseContentAction(actions []action.Action, d action.Data) (action.Action, bool, error) {
+	if len(actions) == 0 {
+		return action.Action{}, false, fmt.Errorf("no actions for item #%d (type %s)", d.ID, d.Type)
+	}
+	first, _, _ := strings.Cut(d.Text, "\n")
+	idx, err := fuzzyfinder.Find(
+		actions,
+		func(i int) string {
+			return fmt.Sprintf("%-10s %s", actions[i].Name, actions[i].Description)
+		},
+		fuzzyfinder.WithHeader(fmt.Sprintf("#%d (%s): %s", d.ID, d.Type, first)),
+		fuzzyfinder.WithPromptString("ACT >> "),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return action.Action{}, false, nil
+	}
+	if err != nil {
+		return action.Action{}, false, err
+	}
+	return actions[idx], true, nil
+}
+
+// runContentAction runs a for d, printing shell actions for the calling shell instead.
+// With shell set, stdout is reserved for those and the output of the other actions goes to stderr.
+func runContentAction(a action.Action, d action.Data, shell, yes bool) error {
+	if a.Shell {
+		cmdLine, err := a.CommandLine(d)
+		if err != nil {
+			return err
+		}
+		if !shell {
+			fmt.Fprintf(os.Stderr, "# %s only works in the calling shell: eval \"$(homie act --shell --action %s)\"\n",
+				a.Name, a.Name)
+		}
+		fmt.Println(cmdLine)
+		return nil
+	}
+
+	if a.Confirm && !yes {
+		cmdLine, err := a.CommandLine(d)
+		if err != nil {
+			return err
+		}
+		ok, err := askConfirmation(os.Stdin, os.Stderr, cmdLine)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	var stdout io.Writer = os.Stdout
+	if shell {
+		stdout = os.Stderr
+	}
+	return a.Run(d, stdout)
+}
+
+// askConfirmation shows cmdLine and reads a yes/no answer (default: no).
+func askConfirmation(in io.Reader, out io.Writer, cmdLine string) (bool, error) {
+	if _, err := fmt.Fprintf(out, "$ %s\nRun? [y/N] ", cmdLine); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func init() {
+	actCmd.Flags().StringP(
+		"action",
+		"a",
+		"",
+		"Run this action instead of choosing from the menu (e.g. open, edit, cd, run)",
+	)
+	actCmd.Flags().BoolP(
+		"yes",
+		"y",
+		false,
+		"Run commands without asking for confirmation",
+	)
+	actCmd.Flags().Bool(
+		"shell",
+		false,
+		"Print shell actions (cd) for the calling shell to evaluate; other output goes to stderr",
+	)
+	actCmd.Flags().BoolP(
+		"list",
+		"l",
+		false,
+		"List the actions available for the item",
+	)
+
+	rootCmd.AddCommand(actCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/editor"
+	"github.com/kaliv0/homie/internal/log"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and edit homie configuration",
+		Long: `Inspect and edit homie configuration
+
+Every setting is resolved in the following order (highest first):
+  1. explicit command-line flag (e.g. --limit, --log-file)
+  2. HOMIE_* environment variable (e.g. HOMIE_LIMIT, HOMIE_USE_WL_CLIPBOARD)
+  3. config file (--config, $XDG_CONFIG_HOME/homie/config.yaml or ~/.homierc)
+  4. built-in default`,
+	}
+
+	configEnvCmd = &cobra.Command{
+		Use:                   "env",
+		Short:                 "List the environment variables overriding each config key",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "KEY\tENV\tVALUE")
+			for _, k := range config.Keys {
+				value, ok := config.LookupEnv(k.Name)
+				if !ok {
+					value = "-"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", k.Name, config.EnvName(k.Name), value)
+			}
+			if err := w.Flush(); err != nil {
+				log.Logger().Fatalf("failed to print environment overrides: %v", err)
+			}
+		},
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:                   "show",
+		Short:                 "Print the effective configuration and the source of each value",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			file := config.FileUsed()
+			if file == "" {
+				file = "-"
+			}
+			fmt.Printf("config file: %s\nprofile: %s\n\n", file, config.Profile())
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+			for _, s := range config.Settings(cmd.Flags()) {
+				_, _ = fmt.Fprintf(w, "%s\t%v\t%s\n", s.Key.Name, s.Value, s.Source)
+			}
+			if err := w.Flush(); err != nil {
+				log.Logger().Fatalf("failed to print configuration: %v", err)
+			}
+		},
+	}
+
+	configGetCmd = &cobra.Command{
+		Use:                   "get <key>",
+		Short:                 "Print the effective value of a config key",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			s, ok := config.Get(args[0], cmd.Flags())
+			if !ok {
+				log.Logger().Fatalf("unknown key %q", args[0])
+			}
+			fmt.Println(s.Value)
+		},
+	}
+
+	configSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a config key to the config file",
+		Long: `Write a config key to the config file
+  The loaded config file (or ~/.homierc) is replaced atomically.
+  With --profile the key is written into that profile's section.`,
+		Args:                  cobra.ExactArgs(2),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			path, err := config.TargetFile()
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if err = config.SetValue(path, args[0], args[1], config.Profile()); err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	configValidateCmd = &cobra.Command{
+		Use:                   "validate",
+		Short:                 "Check the config file for invalid values and unknown keys",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			path := config.FileUsed()
+			if path == "" {
+				fmt.Println("no config file found")
+				return
+			}
+			if !validateConfigFile(path) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	configEditCmd = &cobra.Command{
+		Use:                   "edit",
+		Short:                 "Open the config file in $VISUAL / $EDITOR and validate it afterwards",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			path, err := config.TargetFile()
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if err = editor.Open(path); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if _, err = os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				return
+			}
+			if !validateConfigFile(path) {
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// validateConfigFile prints the problems found in path and reports whether it is valid.
+func validateConfigFile(path string) bool {
+	problems, err := config.ValidateFile(path)
+	if err != nil {
+		log.Logger().Fatal(err)
+	}
+	if len(problems) == 0 {
+		fmt.Printf("%s: ok\n", path)
+		return true
+	}
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", path, p)
+	}
+	return false
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configEnvCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/cycle"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// cycleStatusWidth bounds the text shown by 'homie cycle --status'.
+const cycleStatusWidth = 60
+
+var cycleCmd = &cobra.Command{
+	Use:   "cycle",
+	Short: "Rotate the clipboard through recent history",
+	Long: `Replace the clipboard with the next older history item without opening the history window,
+like yank-pop in Emacs: repeated calls walk back through history (--back walks forward again, both wrap around).
+  The position is kept for cycle_timeout seconds (default: 10) after the last call and as long as the clipboard
+  still holds the cycled item; after that, or with --reset, cycling starts again from the newest item.
+  While cycling the daemon leaves the order of the history alone, so the cycled items don't jump to the top.`,
+	Example: `  homie cycle
+  homie cycle --back
+  tmux display-message "$(homie cycle --status)"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		flags := cmd.Flags()
+		back, err := flags.GetBool("back")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'back' flag: %v", err)
+		}
+		reset, err := flags.GetBool("reset")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'reset' flag: %v", err)
+		}
+		status, err := flags.GetBool("status")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'status' flag: %v", err)
+		}
+
+		statePath, err := config.CycleStatePath()
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		if reset {
+			if err = cycle.Reset(statePath); err != nil {
+				log.Logger().Fatal(err)
+			}
+			return
+		}
+
+		var (
+			s     cycle.State
+			item  storage.ClipboardItem
+			count int
+		)
+		err = withRepository(func(db *storage.Repository) error {
+			s, item, count, err = cycleClipboard(db, statePath, back)
+			return err
+		})
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		if status {
+			first, _, _ := strings.Cut(strings.TrimSpace(item.ClipText), "\n")
+			fmt.Printf("%d/%d  #%d  %s\n", s.Position, count, item.ID, runewidth.Truncate(first, cycleStatusWidth, "…"))
+		}
+	},
+}
+
+// cycleClipboard moves the cycle at statePath one step and puts the item at the new position into the clipboard.
+func cycleClipboard(db *storage.Repository, statePath string, back bool) (cycle.State, storage.ClipboardItem, int, error) {
+	count, err := db.Count()
+	if err != nil {
+		return cycle.State{}, storage.ClipboardItem{}, 0, err
+	}
+	if count < 2 {
+		return cycle.State{}, storage.ClipboardItem{}, 0, errors.New("nothing to cycle through, history has less than 2 items")
+	}
+
+	now := time.Now()
+	s, err := cycle.Load(statePath)
+	if err != nil {
+		return cycle.State{}, storage.ClipboardItem{}, 0, err
+	}
+	pos := 1
+	if s.Active(now, config.CycleTimeout()) && !clipboardChanged(s) {
+		pos = s.Position
+	}
+
+	s.Position = cycle.Next(pos, count, back)
+	item, err := db.Nth(s.Position)
+	if err != nil {
+		return cycle.State{}, storage.ClipboardItem{}, 0, err
+	}
+	s.ID, s.Hash, s.UpdatedAt = item.ID, item.TextHash, now
+	// saved before the clipboard changes, so the daemon already knows the item is cycled
+	if err = cycle.Save(statePath, s); err != nil {
+		return cycle.State{}, storage.ClipboardItem{}, 0, err
+	}
+	if err = writeToClipboard(item.ClipText); err != nil {
+		return cycle.State{}, storage.ClipboardItem{}, 0, err
+	}
+	return s, item, count, nil
+}
+
+// clipboardChanged reports whether something else was copied since the cycle put its item into the clipboard.
+// When the clipboard can't be read the cycle goes on.
+func clipboardChanged(s cycle.State) bool {
+	text, err := readFromClipboard()
+	if err != nil {
+		return false
+	}
+	return storage.TextHash([]byte(text)) != s.Hash
+}
+
+func init() {
+	cycleCmd.Flags().BoolP(
+		"back",
+		"b",
+		false,
+		"Move to the next newer item instead",
+	)
+	cycleCmd.Flags().Bool(
+		"reset",
+		false,
+		"End the current cycle without touching the clipboard",
+	)
+	cycleCmd.Flags().BoolP(
+		"status",
+		"s",
+		false,
+		"Print the position, id and first line of the new clipboard content",
+	)
+
+	rootCmd.AddCommand(cycleCmd)
+}
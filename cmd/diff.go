@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/diff"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <id1> <id2>",
+	Short: "Compare two clipboard history items",
+	Long: `Compare two clipboard history items without temp files
+  The diff is unified by default, --side-by-side puts the items next to each other (like 'diff -y')
+  and --word compares two single-line items word by word (like 'git diff --word-diff')
+  Colors follow --color (auto -> only on a terminal, NO_COLOR disables them) and the theme from the config
+  'homie history --actions' offers the diff for two items selected with <tab>`,
+	Example: `  homie diff 12 15
+  homie diff 12 15 --side-by-side
+  homie diff 40 41 --word`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		sideBySide, err := flags.GetBool("side-by-side")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'side-by-side' flag: %v", err)
+		}
+		words, err := flags.GetBool("word")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'word' flag: %v", err)
+		}
+		context, err := flags.GetInt("context")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'context' flag: %v", err)
+		}
+		width, err := flags.GetInt("width")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'width' flag: %v", err)
+		}
+		color, err := flags.GetString("color")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'color' flag: %v", err)
+		}
+		if sideBySide && words {
+			log.Logger().Fatal("pass either --side-by-side or --word, not both")
+		}
+		theme, err := diffTheme(color)
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+
+		var a, b storage.ClipboardItem
+		err = withRepository(func(db *storage.Repository) error {
+			if a, err = db.Get(parseItemID(args[0])); err != nil {
+				return err
+			}
+			b, err = db.Get(parseItemID(args[1]))
+			return err
+		})
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+
+		opts := diff.Options{
+			Context: context,
+			Theme:   theme,
+			Width:   width,
+			NameA:   diff.ItemName(a.ID, a.TimeStamp),
+			NameB:   diff.ItemName(b.ID, b.TimeStamp),
+		}
+		var out string
+		switch {
+		case words:
+			for _, item := range []storage.ClipboardItem{a, b} {
+				if !diff.SingleLine(item.ClipText) {
+					log.Logger().Fatalf("--word compares single-line items, #%d has %d lines", item.ID, len(diff.Lines(item.ClipText)))
+				}
+			}
+			out = diff.WordDiff(a.ClipText, b.ClipText, opts)
+		case sideBySide:
+			if opts.Width == 0 {
+				opts.Width = terminalWidth()
+			}
+			out = diff.SideBySide(a.ClipText, b.ClipText, opts)
+		default:
+			out = diff.Unified(a.ClipText, b.ClipText, opts)
+		}
+		if out == "" {
+			_, _ = fmt.Fprintf(os.Stderr, "#%d and #%d are identical\n", a.ID, b.ID)
+			return
+		}
+		fmt.Print(out)
+	},
+}
+
+// diffTheme returns the colors for 'homie diff --color <mode>' (nil for plain text).
+func diffTheme(mode string) (highlight.Theme, error) {
+	name := viper.GetString(config.ViperKeyTheme)
+	switch mode {
+	case "always":
+		return highlight.NamedTheme(name), nil
+	case "never":
+		return nil, nil
+	case "auto":
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			return nil, nil
+		}
+		return highlight.LookupTheme(name), nil
+	default:
+		return nil, fmt.Errorf("invalid color mode %q, choose between: auto, always or never", mode)
+	}
+}
+
+// terminalWidth returns the width of the terminal on stdout, or the default side-by-side width.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return diff.DefaultWidth
+}
+
+func init() {
+	diffCmd.Flags().BoolP(
+		"side-by-side",
+		"y",
+		false,
+		"Show the items in two columns",
+	)
+	diffCmd.Flags().BoolP(
+		"word",
+		"w",
+		false,
+		"Compare two single-line items word by word",
+	)
+	diffCmd.Flags().IntP(
+		"context",
+		"U",
+		diff.DefaultContext,
+		"Number of unchanged lines shown around the changes",
+	)
+	diffCmd.Flags().IntP(
+		"width",
+		"W",
+		0,
+		"Width of the side-by-side view (default: the terminal width)",
+	)
+	diffCmd.Flags().String(
+		"color",
+		"auto",
+		"Color the diff: auto (on a terminal), always or never",
+	)
+
+	rootCmd.AddCommand(diffCmd)
+}
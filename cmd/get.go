@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+var (
+	getCmd = &cobra.Command{
+		Use:   "get [n]",
+		Short: "Print a clipboard history item",
+		Long: `Print a clipboard history item without opening the history window
+  n is the position in history (1 -> newest, the default); --id selects an item by its id
+  With --copy the item is written to the clipboard, with --paste it is also pasted into the tmux target pane`,
+		Example: `  homie get 2
+  homie get --id 42 --copy`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			id, err := flags.GetInt("id")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'id' flag: %v", err)
+			}
+			shouldCopy, err := flags.GetBool("copy")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'copy' flag: %v", err)
+			}
+			shouldPaste, err := flags.GetBool("paste")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'paste' flag: %v", err)
+			}
+
+			n := 1
+			if len(args) > 0 {
+				if flags.Changed("id") {
+					log.Logger().Fatal("pass either a position or --id, not both")
+				}
+				if n, err = strconv.Atoi(args[0]); err != nil {
+					log.Logger().Fatalf("invalid position %q: %v", args[0], err)
+				}
+			}
+
+			text, err := fetchItem(n, id, flags.Changed("id"))
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			if !shouldCopy && !shouldPaste {
+				fmt.Print(text)
+				return
+			}
+			if err = writeToClipboard(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if !shouldPaste {
+				return
+			}
+			if err = pasteText(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	pasteCmd = &cobra.Command{
+		Use:   "paste",
+		Short: "Print the clipboard content",
+		Long: `Print the current clipboard content
+  Reads the system clipboard through the configured tool (xclip, xsel or wl-clipboard); the counterpart of 'homie write'
+  With --next the next entry of the paste queue ('homie history --queue') is taken off the queue, copied
+  and pasted (into the tmux target pane if set)`,
+		Example: `  homie paste
+  HOMIE_TARGET_PANE=%1 homie paste --next`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			next, err := cmd.Flags().GetBool("next")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'next' flag: %v", err)
+			}
+			if next {
+				if err = pasteNext(); err != nil {
+					log.Logger().Fatal(err)
+				}
+				return
+			}
+
+			text, err := readFromClipboard()
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			fmt.Print(text)
+		},
+	}
+)
+
+// pasteNext takes the next entry off the paste queue, then copies and pastes it.
+// The entry is claimed before pasting, so concurrent calls paste different entries;
+// a failed paste puts it back at the head of the queue to be retried.
+func pasteNext() error {
+	return withRepository(func(db *storage.Repository) error {
+		entry, err := db.PopQueue()
+		if err != nil {
+			return err
+		}
+		if err = writeToClipboard(entry.Text); err == nil {
+			err = pasteText(entry.Text)
+		}
+		if err != nil {
+			if restoreErr := db.RestoreQueueEntry(entry); restoreErr != nil {
+				log.Logger().Println(restoreErr)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// fetchItem returns the text of the item with id if byID is set, else of the n-th newest item.
+func fetchItem(n, id int, byID bool) (string, error) {
+	item, err := fetchHistoryItem(n, id, byID)
+	if err != nil {
+		return "", err
+	}
+	return item.ClipText, nil
+}
+
+// fetchHistoryItem returns the item with id if byID is set, else the n-th newest item.
+func fetchHistoryItem(n, id int, byID bool) (storage.ClipboardItem, error) {
+	dbPath, err := config.DBPath()
+	if err != nil {
+		return storage.ClipboardItem{}, err
+	}
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		return storage.ClipboardItem{}, err
+	}
+
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Logger().Println(closeErr)
+		}
+	}()
+
+	if byID {
+		return db.Get(id)
+	}
+	return db.Nth(n)
+}
+
+func init() {
+	getCmd.Flags().Int(
+		"id",
+		0,
+		"Select the item by its id instead of its position",
+	)
+	getCmd.Flags().BoolP(
+		"copy",
+		"c",
+		false,
+		"Write the item to the clipboard instead of printing it",
+	)
+	getCmd.Flags().BoolP(
+		"paste",
+		"p",
+		false,
+		"Write the item to the clipboard and paste it (into the tmux target pane if set)",
+	)
+
+	pasteCmd.Flags().BoolP(
+		"next",
+		"n",
+		false,
+		"Take the next entry off the paste queue, copy and paste it",
+	)
+
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(pasteCmd)
+}
@@ -6,29 +6,109 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	gclip "golang.design/x/clipboard"
 
+	"github.com/kaliv0/homie/internal/action"
 	"github.com/kaliv0/homie/internal/clipboard"
 	"github.com/kaliv0/homie/internal/config"
 	"github.com/kaliv0/homie/internal/finder"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/join"
 	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/query"
 	"github.com/kaliv0/homie/internal/storage"
+	"github.com/kaliv0/homie/internal/transform"
 )
 
+// targetPaneEnv names the tmux pane pasteText pastes into, set by the shell and tmux bindings.
+const targetPaneEnv = "HOMIE_TARGET_PANE"
+
 var (
 	listHistoryCmd = &cobra.Command{
-		Use:   "history",
+		Use:   "history [query]",
 		Short: "List clipboard history",
 		Long: `List clipboard history
-  Use <tab> to pin and select multiple entries`,
-		Run: func(cmd *cobra.Command, _ []string) {
-			output, err := fetchDisplayHistory()
+  Use <tab> to pin and select multiple entries
+  With --actions, <enter> opens a menu to copy or paste and stay, diff two items, edit, pin or delete the selection,
+  or to open/run it by content; the window then reopens at the newest item with nothing selected, starting again from
+  the command-line query (the fuzzy finder supports no custom key bindings and doesn't report the typed query)
+  With --edit the selection opens in $VISUAL/$EDITOR first and the edited text is copied or pasted
+  With --transform the selection is rewritten first, e.g. --transform trim,base64-decode
+  With --registers the named registers ('homie reg') are listed as a section before the history
+  With --queue the selection fills the paste queue instead, 'homie paste --next' pastes one entry per call
+  Multiple entries are joined by --separator ('\n', '\t' and '\0' escapes) in --order,
+  optionally through a --template per entry and with --quote for shell arguments
+  The optional query filters the items, e.g. 'homie history type:url after:yesterday@12 before:today'
+  Limitation: predicates (type:, tag:, after:, before:, len, /regex/) filter on the command line only.
+  The query's plain words pre-fill the window's prompt, and whatever is typed there is fuzzy-matched as text,
+  since the fuzzy finder (go-fuzzyfinder) doesn't hand the typed query over for parsing
+` + queryHelp,
+		Run: func(cmd *cobra.Command, args []string) {
+			withActions, err := cmd.Flags().GetBool("actions")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'actions' flag: %v", err)
+			}
+			withEdit, err := cmd.Flags().GetBool("edit")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'edit' flag: %v", err)
+			}
+			saveEdits, err := cmd.Flags().GetBool("save")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'save' flag: %v", err)
+			}
+			transforms, err := cmd.Flags().GetStringSlice("transform")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'transform' flag: %v", err)
+			}
+			withRegisters, err := cmd.Flags().GetBool("registers")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'registers' flag: %v", err)
+			}
+			withQueue, err := cmd.Flags().GetBool("queue")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'queue' flag: %v", err)
+			}
+			types, err := cmd.Flags().GetStringSlice("type")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'type' flag: %v", err)
+			}
+			q, err := query.Parse(withTypes(args, types), time.Now())
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			catalog, err := transform.NewCatalog(config.Transforms())
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			pipeline, err := catalog.Pipeline(transforms)
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			launcher, err := action.NewCatalog(config.Actions())
 			if err != nil {
 				log.Logger().Fatal(err)
 			}
+			output, err := fetchDisplayHistory(q, finder.Options{
+				Actions:    withActions,
+				Edit:       withEdit,
+				SaveEdits:  saveEdits,
+				Transform:  pipeline,
+				Transforms: catalog.All(),
+				Launcher:   launcher,
+				Queue:      withQueue,
+				Registers:  withRegisters,
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if withQueue {
+				reportQueue()
+				return
+			}
 			if len(output) == 0 {
 				return
 			}
@@ -51,15 +131,22 @@ var (
 	}
 
 	clearHistoryCmd = &cobra.Command{
-		Use:                   "clear",
-		Short:                 "Clear clipboard history",
+		Use:   "clear [query]",
+		Short: "Clear clipboard history",
+		Long: `Clear clipboard history
+  With a query only the matching items are deleted, e.g. 'homie clear type:url before:30d'
+` + queryHelp,
 		DisableFlagsInUseLine: true,
-		Run: func(cmd *cobra.Command, _ []string) {
+		Run: func(cmd *cobra.Command, args []string) {
+			q, err := query.Parse(args, time.Now())
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
 			dbPath, err := config.DBPath()
 			if err != nil {
 				log.Logger().Fatal(err)
 			}
-			db, err := storage.NewRepository(dbPath)
+			db, err := storage.Open(dbPath)
 			if err != nil {
 				log.Logger().Fatal(err)
 			}
@@ -70,26 +157,99 @@ var (
 				}
 			}()
 
-			if err := db.Reset(); err != nil {
+			if len(args) == 0 {
+				if err := db.Reset(); err != nil {
+					_ = db.Close()
+					log.Logger().Fatal(err)
+				}
+				return
+			}
+			deleted, err := db.DeleteMatching(q.Filter(true))
+			if err != nil {
 				_ = db.Close()
 				log.Logger().Fatal(err)
 			}
+			if log.Verbose() {
+				log.Logger().Printf("deleted %d clipboard items\n", deleted)
+			}
 		},
 	}
 )
 
-func fetchDisplayHistory() (string, error) {
-	// limit via viper + BindPFlag: --limit/-l if set, else .homierc, else flag default.
-	limit := viper.GetInt("limit")
+// queryHelp documents the query syntax in the help of the commands accepting one.
+const queryHelp = `
+Query syntax:
+  after:<when> before:<when>  age (30m, 2h, 3d, 1w), date (2026-01-01[T15:04]) or today/yesterday[@HH[:MM]]
+  type:<type>                 text, multi-line, url, email, path, json, code, command, number,
+                              color, ip, secret or empty (repeat to allow several)
+  tag:<tag>                   tagged items (repeat to require several)
+  len>N len>=N len<N len<=N len=N
+  /regex/ or /regex/i         Go regular expression (i -> ignore case)
+  other words                 fuzzy search in the history window, substring match elsewhere
+  Predicates are read from the command line; typed into the history window they are plain fuzzy text.`
+
+// withTypes appends a type: predicate per --type value to the query words.
+func withTypes(args, types []string) []string {
+	for _, t := range types {
+		args = append(args, "type:"+t)
+	}
+	return args
+}
+
+// fetchDisplayHistory opens the history window with the behavior flags set in opts.
+func fetchDisplayHistory(q query.Query, opts finder.Options) (string, error) {
+	// limit via viper + BindPFlag: --limit/-l if set, else HOMIE_LIMIT, else .homierc, else flag default.
+	limit := viper.GetInt(config.ViperKeyLimit)
 	if limit <= 0 {
 		limit = storage.DefaultLimit
 	}
 
+	joiner, err := join.New(join.Options{
+		Separator: viper.GetString(config.ViperKeyJoinSeparator),
+		Order:     join.Order(viper.GetString(config.ViperKeyJoinOrder)),
+		Template:  viper.GetString(config.ViperKeyJoinTemplate),
+		Quote:     viper.GetBool(config.ViperKeyJoinQuote),
+	})
+	if err != nil {
+		return "", err
+	}
+
 	dbPath, err := config.DBPath()
 	if err != nil {
 		return "", err
 	}
-	return finder.ListHistory(dbPath, limit)
+	opts.Limit = limit
+	opts.Filter = q.Filter(false)
+	opts.Query = q.Text()
+	opts.Theme = highlight.LookupTheme(viper.GetString(config.ViperKeyTheme))
+	opts.Joiner = joiner
+	opts.Copy = writeToClipboard
+	// outside tmux pasteText prints, which would garble the open window -> no paste action
+	if pasteInPlace() {
+		opts.Paste = func(text string) error {
+			if err := writeToClipboard(text); err != nil {
+				return err
+			}
+			return pasteText(text)
+		}
+	}
+	return finder.ListHistory(dbPath, opts)
+}
+
+// reportQueue tells how many entries the history window left in the paste queue.
+func reportQueue() {
+	var entries []storage.QueueEntry
+	err := withRepository(func(db *storage.Repository) error {
+		var err error
+		entries, err = db.Queue()
+		return err
+	})
+	if err != nil {
+		log.Logger().Fatal(err)
+	}
+	if len(entries) > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "%d items queued, paste them with 'homie paste --next'\n", len(entries))
+	}
 }
 
 func writeToClipboard(text string) error {
@@ -108,15 +268,44 @@ func writeToClipboard(text string) error {
 	return nil
 }
 
+func readFromClipboard() (string, error) {
+	tool, err := clipboardTool()
+	if err != nil {
+		return "", err
+	}
+	if tool != "" {
+		return clipboard.Read(tool)
+	}
+
+	if err = gclip.Init(); err != nil {
+		return "", fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+	return string(gclip.Read(gclip.FmtText)), nil
+}
+
+// clipboardTools maps the supported command-line tools to their executables.
+var clipboardTools = map[string]string{
+	"xclip":        "xclip",
+	"xsel":         "xsel",
+	"wl-clipboard": "wl-copy",
+}
+
 func clipboardTool() (string, error) {
 	if runtime.GOOS != "linux" {
 		return "", nil
 	}
-	for tool, cmd := range map[string]string{
-		"xclip":        "xclip",
-		"xsel":         "xsel",
-		"wl-clipboard": "wl-copy",
-	} {
+	// clipboard_tool takes precedence over the use_<tool> switches
+	if tool := viper.GetString(config.ViperKeyClipboardTool); tool != "" {
+		cmd, ok := clipboardTools[tool]
+		if !ok {
+			return "", fmt.Errorf("unsupported clipboard_tool %q, choose between: xclip, xsel or wl-clipboard", tool)
+		}
+		if _, err := exec.LookPath(cmd); err != nil {
+			return "", fmt.Errorf("%s not found: %w", tool, err)
+		}
+		return tool, nil
+	}
+	for tool, cmd := range clipboardTools {
 		if viper.GetBool("use_" + tool) {
 			if _, err := exec.LookPath(cmd); err != nil {
 				return "", fmt.Errorf("%s not found: %w", tool, err)
@@ -127,8 +316,13 @@ func clipboardTool() (string, error) {
 	return "", fmt.Errorf("command-line tool not selected, choose between: xclip, xsel or wl-clipboard")
 }
 
+// pasteInPlace reports whether pasteText pastes into a tmux pane rather than printing the text.
+func pasteInPlace() bool {
+	return os.Getenv(targetPaneEnv) != ""
+}
+
 func pasteText(text string) error {
-	targetPane := os.Getenv("HOMIE_TARGET_PANE")
+	targetPane := os.Getenv(targetPaneEnv)
 	if targetPane == "" {
 		fmt.Print(text)
 		return nil
@@ -154,7 +348,7 @@ func init() {
 		"limit",
 		"l",
 		storage.DefaultLimit,
-		"Limit the number of clipboard history items displayed",
+		"Number of clipboard history items loaded at a time (scrolling loads more)",
 	)
 	listHistoryCmd.Flags().BoolP(
 		"paste",
@@ -162,11 +356,80 @@ func init() {
 		false,
 		"Paste selected history item",
 	)
+	listHistoryCmd.Flags().BoolP(
+		"actions",
+		"a",
+		false,
+		"Open an action menu (copy/paste and stay, edit, pin, delete) for the selection",
+	)
+
+	listHistoryCmd.Flags().BoolP(
+		"edit",
+		"e",
+		false,
+		"Edit the selection in $VISUAL/$EDITOR before copying or pasting it",
+	)
+	listHistoryCmd.Flags().Bool(
+		"save",
+		false,
+		"Save text changed by --edit or the edit-then-copy action as a new history entry",
+	)
+	listHistoryCmd.Flags().BoolP(
+		"registers",
+		"r",
+		false,
+		"List the named registers as a section before the history",
+	)
+	listHistoryCmd.Flags().Bool(
+		"queue",
+		false,
+		"Put the selected items into the paste queue, in --order, instead of copying them",
+	)
+	listHistoryCmd.Flags().StringSlice(
+		"type",
+		nil,
+		"Only items of these content types (same as type: in the query)",
+	)
+	listHistoryCmd.Flags().StringSlice(
+		"transform",
+		nil,
+		"Transforms applied to the selection in order: shell-quote, json-escape, json-pretty, url-encode, url-decode,\n"+
+			"base64-encode, base64-decode, trim, upper, lower, strip-ansi or one defined under 'transforms' in the config",
+	)
+	listHistoryCmd.Flags().StringP(
+		"separator",
+		"s",
+		" ",
+		`Separator between multiple selected items (\n, \t, \0 escapes)`,
+	)
+	listHistoryCmd.Flags().String(
+		"order",
+		string(join.OrderSelection),
+		"Order of multiple selected items: selection, chronological (oldest first) or reverse (newest first)",
+	)
+	listHistoryCmd.Flags().StringP(
+		"template",
+		"t",
+		"",
+		`Go template applied to each selected item, e.g. '"{{.Text}}"'`,
+	)
+	listHistoryCmd.Flags().BoolP(
+		"quote",
+		"q",
+		false,
+		"Shell-quote each selected item",
+	)
 
-	if err := viper.BindPFlag("limit", listHistoryCmd.Flags().Lookup("limit")); err != nil {
-		log.Logger().Fatalf("failed to bind 'limit' flag to viper: %v", err)
+	// the keys overridden by history flags (limit, join_*) follow them through viper
+	for _, k := range config.Keys {
+		f := listHistoryCmd.Flags().Lookup(k.Flag)
+		if k.Flag == "" || f == nil {
+			continue
+		}
+		if err := viper.BindPFlag(k.Name, f); err != nil {
+			log.Logger().Fatalf("failed to bind '%s' flag to viper: %v", k.Flag, err)
+		}
 	}
-	viper.SetDefault("use_xclip", false)
 
 	rootCmd.AddCommand(listHistoryCmd)
 	rootCmd.AddCommand(clearHistoryCmd)
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/query"
+	"github.com/kaliv0/homie/internal/render"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// listPageSize bounds the items loaded at once while streaming the output.
+const listPageSize = 500
+
+var listCmd = &cobra.Command{
+	Use:   "list [query]",
+	Short: "Print clipboard history",
+	Long: `Print clipboard history (newest first) without opening the history window
+  Takes the same query as 'homie history'; plain words must occur in the text (ignoring case).
+  Formats: plain, json, ndjson, tsv (id, time, type, escaped text) and template (--template '{{.ID}} {{oneline .Text}}').
+  Template fields: .ID .Text .Time .Type .Copies .Pinned; functions: oneline, json
+` + queryHelp,
+	Example: `  homie list -0 | fzf --read0
+  homie list --format json type:url after:1w | jq -r '.[].text'
+  homie list --all --format template --template '{{.ID}}{{"\t"}}{{oneline .Text}}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		limit, err := flags.GetInt("limit")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'limit' flag: %v", err)
+		}
+		if !flags.Changed("limit") {
+			limit = viper.GetInt(config.ViperKeyLimit)
+		}
+		all, err := flags.GetBool("all")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'all' flag: %v", err)
+		}
+		offset, err := flags.GetInt("offset")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'offset' flag: %v", err)
+		}
+		since, err := flags.GetString("since")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'since' flag: %v", err)
+		}
+		format, err := flags.GetString("format")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'format' flag: %v", err)
+		}
+		tmpl, err := flags.GetString("template")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'template' flag: %v", err)
+		}
+		nullSep, err := flags.GetBool("null")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'null' flag: %v", err)
+		}
+		types, err := flags.GetStringSlice("type")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'type' flag: %v", err)
+		}
+
+		now := time.Now()
+		q, err := query.Parse(withTypes(args, types), now)
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		if since != "" {
+			after, err := query.ParseTime(since, now)
+			if err != nil {
+				log.Logger().Fatalf("invalid --since value %q: %v", since, err)
+			}
+			if after.After(q.After) {
+				q.After = after
+			}
+		}
+		// --template alone implies the template format
+		if tmpl != "" && !flags.Changed("format") {
+			format = string(render.FormatTemplate)
+		}
+
+		w, err := render.NewWriter(os.Stdout, render.Options{
+			Format:   render.Format(format),
+			Template: tmpl,
+			NullSep:  nullSep,
+		})
+		if err != nil {
+			log.Logger().Fatal(err)
+		}
+		if all {
+			limit = -1
+		} else if limit <= 0 {
+			limit = storage.DefaultLimit
+		}
+		if err := listHistory(w, q.Filter(true), max(offset, 0), limit); err != nil {
+			log.Logger().Fatal(err)
+		}
+	},
+}
+
+// listHistory writes up to limit (-1 -> all) items matching filter, starting at offset.
+func listHistory(w *render.Writer, filter storage.Filter, offset, limit int) error {
+	dbPath, err := config.DBPath()
+	if err != nil {
+		return err
+	}
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Logger().Println(closeErr)
+		}
+	}()
+
+	// only the first page is read by offset, the rest continues after its last item
+	var cursor *storage.Cursor
+	for limit != 0 {
+		size := listPageSize
+		if limit > 0 {
+			size = min(size, limit)
+		}
+		var page []storage.ClipboardItem
+		if cursor == nil {
+			page, err = db.Search(filter, offset, size)
+		} else {
+			page, err = db.SearchAfter(filter, *cursor, size)
+		}
+		if err != nil {
+			return err
+		}
+		if err = w.Write(page); err != nil {
+			return err
+		}
+		if len(page) < size {
+			break
+		}
+		next := storage.CursorAfter(page[len(page)-1])
+		cursor = &next
+		if limit > 0 {
+			limit -= len(page)
+		}
+	}
+	return w.Close()
+}
+
+func init() {
+	listCmd.Flags().IntP("limit", "l", storage.DefaultLimit, "Maximum number of items (default: the configured limit)")
+	listCmd.Flags().BoolP("all", "a", false, "List every matching item")
+	listCmd.Flags().Int("offset", 0, "Skip the newest n matching items")
+	listCmd.Flags().String("since", "", "Only items captured after this time (30m, 2d, 2026-01-01, yesterday@12)")
+	listCmd.Flags().StringSlice("type", nil, "Only items of these content types (same as type: in the query)")
+	listCmd.Flags().StringP("format", "f", string(render.FormatPlain), "Output format: plain, json, ndjson, tsv or template")
+	listCmd.Flags().StringP("template", "t", "", "Go template executed per item (implies --format template)")
+	listCmd.Flags().BoolP("null", "0", false, "End items with NUL instead of a newline")
+
+	rootCmd.AddCommand(listCmd)
+}
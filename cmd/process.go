@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/kaliv0/homie/internal/clipboard"
 	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/cycle"
 	"github.com/kaliv0/homie/internal/daemon"
 	"github.com/kaliv0/homie/internal/log"
 	"github.com/kaliv0/homie/internal/storage"
@@ -62,6 +65,11 @@ var (
 		Use:    "run",
 		Hidden: true,
 		Run: func(cmd *cobra.Command, _ []string) {
+			// register before the pidfile is written -> an early reload request can't kill the daemon
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, daemon.ReloadSignal)
+			defer signal.Stop(reload)
+
 			lock, err := daemon.Acquire()
 			if err != nil {
 				if errors.Is(err, daemon.ErrAlreadyRunning) {
@@ -102,32 +110,75 @@ var (
 				log.Logger().Fatal(err)
 			}
 
-			cfg := storage.CleanupConfig{
-				CleanUp: viper.GetBool("clean_up"),
-				TTL:     viper.GetInt("ttl"),
-				MaxSize: viper.GetInt("max_size"),
-				Limit:   viper.GetInt("limit"),
-			}
-			if err := storage.CleanOldHistory(db, cfg); err != nil {
+			if err := storage.CleanOldHistory(db, cleanupConfig()); err != nil {
 				log.Logger().Println(err)
 			}
 
+			// items put back by 'homie cycle' keep their place in history
+			statePath, err := config.CycleStatePath()
+			if err != nil {
+				_ = db.Close()
+				log.Logger().Fatal(err)
+			}
+			history := cycle.NewWriter(db, statePath, config.CycleTimeout())
+
 			// Ignore SIGHUP so the daemon survives terminal/session closure (e.g. tmux exit)
 			signal.Ignore(syscall.SIGHUP)
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 
+			// stop the snippet watcher and wait for an in-flight sync before db.Close() runs
+			var wg sync.WaitGroup
+			defer func() {
+				stop()
+				wg.Wait()
+			}()
+			// resolved here: the watcher must not read viper while a reload rewrites it
+			snippetsDir, err := config.SnippetsDir()
+			if err != nil {
+				log.Logger().Println(err)
+			}
+			wg.Go(func() {
+				watchSnippets(ctx, db, snippetsDir)
+			})
+
 			if err := gclip.Init(); err != nil {
 				_ = db.Close()
 				log.Logger().Fatal(fmt.Errorf("failed to initialize clipboard: %w", err))
 			}
-			if err := clipboard.TrackClipboard(ctx, db, gclip.Watch(ctx, gclip.FmtText)); err != nil {
+			// reloads run on the tracking loop, the only other reader of viper in the daemon
+			onReload := func() {
+				reloadConfig(cmd, db, history)
+			}
+			if err := clipboard.TrackClipboard(ctx, history, gclip.Watch(ctx, gclip.FmtText), reload, onReload); err != nil {
 				_ = db.Close()
 				log.Logger().Fatal(err)
 			}
 		},
 	}
 
+	reloadCmd = &cobra.Command{
+		Use:   "reload",
+		Short: "Reload the config in the running clipboard manager",
+		Long: `Reload the config in the running clipboard manager
+  Picks up retention (clean_up, ttl, max_size, limit), logging (verbose, log_file) and cycle_timeout changes.
+  An invalid config is rejected and the daemon keeps the previous one; a removed config file restores the defaults.
+  db_path, pid_file and snippets_dir changes require 'homie restart'.
+  The clipboard tool (clipboard_tool, use_*), join and theme settings are read by each command and need no reload.`,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if path := config.FileUsed(); path != "" && !validateConfigFile(path) {
+				os.Exit(1)
+			}
+			if err := daemon.Reload(); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if log.Verbose() {
+				log.Logger().Println("homie daemon reloading config")
+			}
+		},
+	}
+
 	stopCmd = &cobra.Command{
 		Use:                   "stop",
 		Short:                 "Stop clipboard manager",
@@ -147,6 +198,15 @@ var (
 		Short:                 "Show clipboard manager daemon status",
 		DisableFlagsInUseLine: true,
 		Run: func(cmd *cobra.Command, _ []string) {
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'all' flag: %v", err)
+			}
+			if all {
+				printAllStatuses()
+				return
+			}
+
 			running, pid, err := daemon.Status()
 			if err != nil {
 				log.Logger().Fatal(err)
@@ -160,12 +220,68 @@ var (
 	}
 )
 
+// reloadConfig re-reads the config and applies it to the running daemon;
+// the cycle timeout is handed to history, which reads no config itself.
+func reloadConfig(cmd *cobra.Command, db *storage.Repository, history *cycle.Writer) {
+	if err := config.Reload(); err != nil {
+		log.Logger().Printf("config reload rejected, keeping previous config: %v\n", err)
+		return
+	}
+	log.ConfigureFromFlags(cmd.Flags())
+	history.SetTimeout(config.CycleTimeout())
+	if err := storage.CleanOldHistory(db, cleanupConfig()); err != nil {
+		log.Logger().Println(err)
+	}
+	if log.Verbose() {
+		log.Logger().Println("homie daemon config reloaded")
+	}
+}
+
+func cleanupConfig() storage.CleanupConfig {
+	return storage.CleanupConfig{
+		CleanUp: viper.GetBool(config.ViperKeyCleanUp),
+		TTL:     viper.GetInt(config.ViperKeyTTL),
+		MaxSize: viper.GetInt(config.ViperKeyMaxSize),
+		Limit:   viper.GetInt(config.ViperKeyLimit),
+	}
+}
+
+func printAllStatuses() {
+	statuses, err := daemon.StatusAll()
+	if err != nil {
+		log.Logger().Fatal(err)
+	}
+	found := false
+	for _, s := range statuses {
+		if !s.Running {
+			continue
+		}
+		found = true
+		fmt.Printf("%s: running (pid %d)\n", s.Profile, s.PID)
+	}
+	if !found {
+		fmt.Println("not running")
+	}
+}
+
 func runDaemon(cmd *cobra.Command) {
 	cmdName := cmd.Root().Name()
-	daemonCmd := exec.Command(cmdName, "run")
+	args := []string{"run"}
+	if profile := config.Profile(); profile != config.DefaultProfile {
+		args = append(args, "--profile", profile)
+	}
+	// the daemon runs detached -> pass the config file it should load explicitly
+	if path := config.FileUsed(); path != "" {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			log.Logger().Fatalf("failed to resolve config file %q: %v", path, err)
+		}
+		args = append(args, "--config", absPath)
+	}
+	daemonCmd := exec.Command(cmdName, args...)
 	daemonCmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 	if err := daemonCmd.Start(); err != nil {
-		log.Logger().Fatalf("failed to start daemon process (command=%q run): %v", cmdName, err)
+		log.Logger().Fatalf("failed to start daemon process (command=%q %v): %v", cmdName, args, err)
 	}
 	if err := daemonCmd.Process.Release(); err != nil {
 		log.Logger().Printf("failed to release daemon process: %v\n", err)
@@ -173,9 +289,12 @@ func runDaemon(cmd *cobra.Command) {
 }
 
 func init() {
+	statusCmd.Flags().BoolP("all", "a", false, "List running daemons of every profile")
+
 	rootCmd.AddCommand(startDaemonCmd)
 	rootCmd.AddCommand(restartDaemonCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(reloadCmd)
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(statusCmd)
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// queueListWidth bounds the text column of 'homie queue list'.
+const queueListWidth = 60
+
+var (
+	queueCmd = &cobra.Command{
+		Use:   "queue",
+		Short: "Manage the paste queue",
+		Long: `Manage the paste queue: texts selected with 'homie history --queue', waiting to be pasted one by one
+with 'homie paste --next', e.g. to fill a form or a console field by field.
+The queue keeps copies of the texts, so the history clean-up doesn't empty it.`,
+	}
+
+	queueListCmd = &cobra.Command{
+		Use:                   "list",
+		Aliases:               []string{"ls"},
+		Short:                 "List the paste queue, next entry first",
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var entries []storage.QueueEntry
+			err := withRepository(func(db *storage.Repository) error {
+				var err error
+				entries, err = db.Queue()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			rows := make([][]string, 0, len(entries))
+			for i, entry := range entries {
+				rows = append(rows, []string{strconv.Itoa(i + 1), entry.QueuedAt.Local().Format(time.DateTime), entry.Text})
+			}
+			if err = printTextTable([]string{"POS", "QUEUED", "TEXT"}, rows, queueListWidth); err != nil {
+				log.Logger().Fatalf("failed to print paste queue: %v", err)
+			}
+		},
+	}
+
+	queueClearCmd = &cobra.Command{
+		Use:                   "clear",
+		Short:                 "Empty the paste queue",
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var cleared int64
+			err := withRepository(func(db *storage.Repository) error {
+				var err error
+				cleared, err = db.ClearQueue()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if log.Verbose() {
+				log.Logger().Printf("dropped %d queued texts\n", cleared)
+			}
+		},
+	}
+)
+
+func init() {
+	queueCmd.AddCommand(queueListCmd, queueClearCmd)
+	rootCmd.AddCommand(queueCmd)
+}
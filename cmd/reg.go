@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/render"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// regListWidth bounds the text column of 'homie reg list'.
+const regListWidth = 60
+
+var (
+	regCmd = &cobra.Command{
+		Use:   "reg",
+		Short: "Manage named registers",
+		Long: `Manage named registers: stable slots (a-z or any name without spaces) kept apart from the history,
+e.g. for the current ticket id. The clean-up and 'homie clear' never touch them.
+'homie history --registers' lists them as a section before the history.`,
+	}
+
+	regSetCmd = &cobra.Command{
+		Use:   "set <name> [text|-]",
+		Short: "Store text in a register",
+		Long: `Store text in a register, replacing its content
+  Without text the current clipboard content is stored, with - stdin is read (a trailing newline is dropped)`,
+		Example: `  homie reg set ticket HOM-42
+  git rev-parse HEAD | homie reg set c -
+  homie reg set a`,
+		Args:                  cobra.RangeArgs(1, 2),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			text, err := registerInput(args[1:])
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			err = withRepository(func(db *storage.Repository) error {
+				return db.SetRegister(args[0], text)
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	regGetCmd = &cobra.Command{
+		Use:   "get <name>",
+		Short: "Print a register",
+		Long: `Print the content of a register
+  With --copy it is written to the clipboard instead, with --paste it is also pasted into the tmux target pane`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			shouldCopy, err := flags.GetBool("copy")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'copy' flag: %v", err)
+			}
+			shouldPaste, err := flags.GetBool("paste")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'paste' flag: %v", err)
+			}
+
+			var reg storage.Register
+			err = withRepository(func(db *storage.Repository) error {
+				reg, err = db.GetRegister(args[0])
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			if !shouldCopy && !shouldPaste {
+				fmt.Print(reg.Text)
+				return
+			}
+			if err = writeToClipboard(reg.Text); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if !shouldPaste {
+				return
+			}
+			if err = pasteText(reg.Text); err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	regListCmd = &cobra.Command{
+		Use:                   "list",
+		Aliases:               []string{"ls"},
+		Short:                 "List the registers",
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var regs []storage.Register
+			err := withRepository(func(db *storage.Repository) error {
+				var err error
+				regs, err = db.Registers()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			rows := make([][]string, 0, len(regs))
+			for _, reg := range regs {
+				rows = append(rows, []string{reg.Name, reg.UpdatedAt.Local().Format(time.DateTime), reg.Text})
+			}
+			if err = printTextTable([]string{"NAME", "UPDATED", "TEXT"}, rows, regListWidth); err != nil {
+				log.Logger().Fatalf("failed to print registers: %v", err)
+			}
+		},
+	}
+
+	regRmCmd = &cobra.Command{
+		Use:                   "rm <name>...",
+		Short:                 "Delete registers",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := withRepository(func(db *storage.Repository) error {
+				for _, name := range args {
+					if err := db.DeleteRegister(name); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+)
+
+// registerInput returns the text for 'reg set': the argument, stdin for "-" or the clipboard content.
+func registerInput(args []string) (string, error) {
+	if len(args) == 0 {
+		return readFromClipboard()
+	}
+	if args[0] != "-" {
+		return args[0], nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// withRepository runs fn on the database of the active profile.
+func withRepository(fn func(db *storage.Repository) error) error {
+	dbPath, err := config.DBPath()
+	if err != nil {
+		return err
+	}
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Logger().Println(closeErr)
+		}
+	}()
+	return fn(db)
+}
+
+// printTextTable prints rows under header in aligned columns. Every cell is kept on one line,
+// and the last one, the text, is cut to width cells.
+func printTextTable(header []string, rows [][]string, width int) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = render.EscapeField(cell)
+		}
+		row[len(row)-1] = runewidth.Truncate(row[len(row)-1], width, "…")
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func init() {
+	regGetCmd.Flags().BoolP(
+		"copy",
+		"c",
+		false,
+		"Write the register to the clipboard instead of printing it",
+	)
+	regGetCmd.Flags().BoolP(
+		"paste",
+		"p",
+		false,
+		"Write the register to the clipboard and paste it (into the tmux target pane if set)",
+	)
+
+	regCmd.AddCommand(regSetCmd, regGetCmd, regListCmd, regRmCmd)
+	rootCmd.AddCommand(regCmd)
+}
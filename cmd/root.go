@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/kaliv0/homie/internal/config"
 	"github.com/kaliv0/homie/internal/log"
@@ -11,7 +12,7 @@ var rootCmd = &cobra.Command{
 	Use:   "homie",
 	Short: "Terminal-based clipboard manager",
 	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-		// Single place for loading .homierc -> reused in children
+		// Single place for loading the config file -> reused in children
 		if err := config.ReadConfig(); err != nil {
 			return err
 		}
@@ -49,6 +50,24 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose diagnostic messages")
 	rootCmd.PersistentFlags().String("log-file", "", "append logs to file")
+	rootCmd.PersistentFlags().String(
+		"profile",
+		"",
+		"use a separate history, daemon and log per profile (env HOMIE_PROFILE)",
+	)
+
+	rootCmd.PersistentFlags().String(
+		"config",
+		"",
+		"config file (default $XDG_CONFIG_HOME/homie/config.yaml, then ~/.homierc)",
+	)
+
+	if err := viper.BindPFlag(config.ViperKeyProfile, rootCmd.PersistentFlags().Lookup("profile")); err != nil {
+		log.Logger().Fatalf("failed to bind 'profile' flag to viper: %v", err)
+	}
+	if err := viper.BindPFlag(config.ViperKeyConfigFile, rootCmd.PersistentFlags().Lookup("config")); err != nil {
+		log.Logger().Fatalf("failed to bind 'config' flag to viper: %v", err)
+	}
 }
 
 // Execute runs the root cobra command.
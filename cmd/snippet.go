@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/kaliv0/homie/internal/config"
+	"github.com/kaliv0/homie/internal/finder"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/snippet"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// snippetListWidth bounds the text column of 'homie snippet list'.
+const snippetListWidth = 50
+
+var (
+	snippetCmd = &cobra.Command{
+		Use:   "snippet [query]",
+		Short: "Pick, expand and copy a snippet",
+		Long: `Pick a snippet in the fuzzy finder, expand its placeholders and copy the result
+  Snippets are saved with 'homie snippet save/add' or loaded from the YAML/TOML files in the snippets directory
+  (snippets_dir, default: $XDG_CONFIG_HOME/homie/snippets), which the daemon watches for changes.
+  Placeholders are filled in when the snippet is used:
+    {{date}} {{date "15:04"}}   current date or time (Go layout)
+    {{clipboard}}               current clipboard content
+    {{env "USER"}}              environment variable
+    {{prompt "host"}}           asks for a value, {{prompt "host" "localhost"}} with a default
+  Other template syntax is rejected; write {{"{{"}} for literal braces.`,
+		Example: `  homie snippet
+  homie snippet deploy --paste
+  homie snippet save 42 --name deploy --tag ops`,
+		Run: func(cmd *cobra.Command, args []string) {
+			shouldPaste, err := cmd.Flags().GetBool("paste")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'paste' flag: %v", err)
+			}
+
+			var snippets []storage.Snippet
+			err = withRepository(func(db *storage.Repository) error {
+				syncSnippets(db)
+				snippets, err = db.Snippets()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if len(snippets) == 0 {
+				log.Logger().Fatal("no snippets yet, add one with 'homie snippet save <id> --name <name>' " +
+					"or put YAML/TOML files into the snippets directory")
+			}
+
+			s, ok, err := finder.PickSnippet(snippets, strings.Join(args, " "),
+				highlight.LookupTheme(viper.GetString(config.ViperKeyTheme)))
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if !ok {
+				return
+			}
+			text, err := expandSnippet(s)
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if err = writeToClipboard(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if !shouldPaste {
+				return
+			}
+			if err = pasteText(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	snippetSaveCmd = &cobra.Command{
+		Use:   "save <id>",
+		Short: "Save a history item as a snippet",
+		Long: `Save the history item with the given id (shown in the history preview) as a snippet
+  An existing snippet of the same name is replaced`,
+		Example: `  homie snippet save 42 --name deploy --tag ops,k8s`,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name, tags := snippetFlags(cmd)
+			id := parseItemID(args[0])
+			err := withRepository(func(db *storage.Repository) error {
+				item, err := db.Get(id)
+				if err != nil {
+					return err
+				}
+				return saveSnippet(db, storage.Snippet{Name: name, Text: item.ClipText, Tags: tags})
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	snippetAddCmd = &cobra.Command{
+		Use:   "add <name> [text|-]",
+		Short: "Create a snippet",
+		Long: `Create a snippet, replacing an existing one of the same name
+  Without text the current clipboard content is used, with - stdin is read (a trailing newline is dropped)`,
+		Example: `  homie snippet add standup 'Yesterday: {{prompt "yesterday"}}, today: {{prompt "today"}}' --tag work
+  homie snippet add license - < LICENSE-HEADER`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			_, tags := snippetFlags(cmd)
+			text, err := registerInput(args[1:])
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			err = withRepository(func(db *storage.Repository) error {
+				return saveSnippet(db, storage.Snippet{Name: args[0], Text: text, Tags: tags})
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	snippetListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the snippets",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			_, tags := snippetFlags(cmd)
+			var snippets []storage.Snippet
+			err := withRepository(func(db *storage.Repository) error {
+				syncSnippets(db)
+				var err error
+				snippets, err = db.Snippets(tags...)
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			rows := make([][]string, 0, len(snippets))
+			for _, s := range snippets {
+				source := "-"
+				if s.Source != "" {
+					source = filepath.Base(s.Source)
+				}
+				rows = append(rows, []string{s.Name, strings.Join(s.Tags, ","), source, s.Text})
+			}
+			if err = printTextTable([]string{"NAME", "TAGS", "SOURCE", "TEXT"}, rows, snippetListWidth); err != nil {
+				log.Logger().Fatalf("failed to print snippets: %v", err)
+			}
+		},
+	}
+
+	snippetExpandCmd = &cobra.Command{
+		Use:   "expand <name>",
+		Short: "Print a snippet with its placeholders filled in",
+		Long: `Print a snippet with its placeholders filled in
+  With --copy the result is written to the clipboard instead, with --paste it is also pasted into the tmux target pane`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			shouldCopy, err := flags.GetBool("copy")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'copy' flag: %v", err)
+			}
+			shouldPaste, err := flags.GetBool("paste")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'paste' flag: %v", err)
+			}
+
+			var s storage.Snippet
+			err = withRepository(func(db *storage.Repository) error {
+				syncSnippets(db)
+				s, err = db.Snippet(args[0])
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			text, err := expandSnippet(s)
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			if !shouldCopy && !shouldPaste {
+				fmt.Print(text)
+				return
+			}
+			if err = writeToClipboard(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+			if !shouldPaste {
+				return
+			}
+			if err = pasteText(text); err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	snippetRmCmd = &cobra.Command{
+		Use:                   "rm <name>...",
+		Short:                 "Delete saved snippets",
+		Long:                  "Delete saved snippets (snippets from files are removed by editing the file)",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := withRepository(func(db *storage.Repository) error {
+				for _, name := range args {
+					if err := db.DeleteSnippet(name); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	snippetSyncCmd = &cobra.Command{
+		Use:                   "sync",
+		Short:                 "Reload the snippet files",
+		Long:                  "Reload the snippet files and report problems (the daemon does this whenever they change)",
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var (
+				n        int
+				shadowed []string
+				loadErr  error
+			)
+			err := withRepository(func(db *storage.Repository) error {
+				dir, err := config.SnippetsDir()
+				if err != nil {
+					return err
+				}
+				n, shadowed, loadErr = loadSnippetFiles(db, dir)
+				return nil
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			fmt.Printf("%d snippets loaded from files\n", n)
+			for _, name := range shadowed {
+				fmt.Printf("%s: shadowed by the saved snippet of the same name\n", name)
+			}
+			if loadErr != nil {
+				log.Logger().Fatal(loadErr)
+			}
+		},
+	}
+)
+
+// snippetFlags returns the --name and --tag flags of the snippet subcommands that have them.
+func snippetFlags(cmd *cobra.Command) (string, []string) {
+	var name string
+	if cmd.Flags().Lookup("name") != nil {
+		var err error
+		if name, err = cmd.Flags().GetString("name"); err != nil {
+			log.Logger().Fatalf("failed to get 'name' flag: %v", err)
+		}
+	}
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		log.Logger().Fatalf("failed to get 'tag' flag: %v", err)
+	}
+	return name, tags
+}
+
+// saveSnippet stores s after checking its placeholders.
+func saveSnippet(db *storage.Repository, s storage.Snippet) error {
+	if err := snippet.Check(s.Text); err != nil {
+		return fmt.Errorf("invalid placeholders in snippet %q: %w", s.Name, err)
+	}
+	return db.SaveSnippet(s)
+}
+
+// loadSnippetFiles syncs the snippet files into the database. It returns the number of file snippets in use
+// and the names of those shadowed by saved snippets; problems with single files don't stop the others from loading.
+func loadSnippetFiles(db *storage.Repository, dir string) (int, []string, error) {
+	snippets, loadErr := snippet.Load(dir)
+	shadowed, err := db.SyncFileSnippets(snippets)
+	if err != nil {
+		return 0, nil, errors.Join(loadErr, err)
+	}
+	return len(snippets) - len(shadowed), shadowed, loadErr
+}
+
+// syncSnippets refreshes the snippets from the files in the configured directory, logging problems instead of failing.
+func syncSnippets(db *storage.Repository) {
+	dir, err := config.SnippetsDir()
+	if err != nil {
+		log.Logger().Println(err)
+		return
+	}
+	syncSnippetDir(db, dir)
+}
+
+// syncSnippetDir refreshes the snippets from the files in dir, logging problems instead of failing.
+func syncSnippetDir(db *storage.Repository, dir string) {
+	if _, _, err := loadSnippetFiles(db, dir); err != nil {
+		log.Logger().Println(err)
+	}
+}
+
+// watchSnippets syncs the snippet files in dir at start and again whenever they change, until ctx is done.
+// The caller resolves dir, since the config must not be read here while a reload rewrites it;
+// a directory created or configured later is picked up on the next start.
+func watchSnippets(ctx context.Context, db *storage.Repository, dir string) {
+	if dir == "" {
+		return
+	}
+	syncSnippetDir(db, dir)
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	if err := snippet.Watch(ctx, dir, func() { syncSnippetDir(db, dir) }); err != nil {
+		log.Logger().Println(err)
+	}
+}
+
+// expandSnippet fills in the placeholders of s; prompts go to stderr, answers come from stdin.
+func expandSnippet(s storage.Snippet) (string, error) {
+	in := bufio.NewReader(os.Stdin)
+	e := snippet.NewExpander(readFromClipboard, func(name, def string) (string, error) {
+		return promptValue(in, os.Stderr, name, def)
+	})
+	text, err := e.Expand(s.Text)
+	if err != nil {
+		return "", fmt.Errorf("snippet %q: %w", s.Name, err)
+	}
+	return text, nil
+}
+
+// promptValue asks for the value of name; an empty answer takes def.
+func promptValue(in *bufio.Reader, out io.Writer, name, def string) (string, error) {
+	prompt := name + ": "
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", name, def)
+	}
+	if _, err := fmt.Fprint(out, prompt); err != nil {
+		return "", err
+	}
+	answer, err := in.ReadString('\n')
+	if errors.Is(err, io.EOF) && answer == "" {
+		return "", fmt.Errorf("no value for %q", name)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read value for %q: %w", name, err)
+	}
+	if answer = strings.TrimRight(answer, "\r\n"); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+func init() {
+	snippetCmd.Flags().BoolP(
+		"paste",
+		"p",
+		false,
+		"Paste the expanded snippet (into the tmux target pane if set)",
+	)
+
+	snippetSaveCmd.Flags().StringP("name", "n", "", "Name of the snippet")
+	if err := snippetSaveCmd.MarkFlagRequired("name"); err != nil {
+		log.Logger().Fatal(err)
+	}
+	for _, c := range []*cobra.Command{snippetSaveCmd, snippetAddCmd, snippetListCmd} {
+		c.Flags().StringSliceP("tag", "t", nil, "Tags of the snippet (repeat or separate by commas)")
+	}
+	snippetListCmd.Flags().Lookup("tag").Usage = "Only snippets with all of these tags"
+
+	snippetExpandCmd.Flags().BoolP(
+		"copy",
+		"c",
+		false,
+		"Write the expanded snippet to the clipboard instead of printing it",
+	)
+	snippetExpandCmd.Flags().BoolP(
+		"paste",
+		"p",
+		false,
+		"Write the expanded snippet to the clipboard and paste it (into the tmux target pane if set)",
+	)
+
+	snippetCmd.AddCommand(snippetSaveCmd, snippetAddCmd, snippetListCmd, snippetExpandCmd, snippetRmCmd, snippetSyncCmd)
+	rootCmd.AddCommand(snippetCmd)
+}
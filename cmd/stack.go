@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// stackListWidth bounds the text column of 'homie stack'.
+const stackListWidth = 60
+
+var (
+	pushCmd = &cobra.Command{
+		Use:   "push [text|-]",
+		Short: "Save the clipboard on the stack",
+		Long: `Save the current clipboard content on top of the clipboard stack, e.g. before copying something temporary;
+'homie pop' puts it back. The stack is kept apart from the history, so the clean-up never touches it.
+  With text that text is pushed instead, with - stdin is read (a trailing newline is dropped)`,
+		Example: `  homie push && echo temp | homie write && homie pop
+  homie push "draft reply"`,
+		Args:                  cobra.MaximumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			text, err := registerInput(args)
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			var depth int
+			err = withRepository(func(db *storage.Repository) error {
+				depth, err = db.PushStack(text)
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if log.Verbose() {
+				log.Logger().Printf("pushed %d characters, stack depth %d\n", len(text), depth)
+			}
+		},
+	}
+
+	popCmd = &cobra.Command{
+		Use:   "pop",
+		Short: "Restore the clipboard from the stack",
+		Long: `Take the top entry off the clipboard stack and write it back to the clipboard
+  With --print it is printed instead of restored`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			shouldPrint, err := cmd.Flags().GetBool("print")
+			if err != nil {
+				log.Logger().Fatalf("failed to get 'print' flag: %v", err)
+			}
+			err = withRepository(func(db *storage.Repository) error {
+				// claimed first, so concurrent pops restore different entries
+				entry, err := db.PopStack()
+				if err != nil {
+					return err
+				}
+				if shouldPrint {
+					fmt.Print(entry.Text)
+					return nil
+				}
+				if err = writeToClipboard(entry.Text); err != nil {
+					// a failed clipboard write puts it back on the stack
+					if restoreErr := db.RestoreStackEntry(entry); restoreErr != nil {
+						log.Logger().Println(restoreErr)
+					}
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	stackCmd = &cobra.Command{
+		Use:   "stack",
+		Short: "List the clipboard stack",
+		Long: `List the clipboard stack filled by 'homie push', top entry (the next one 'homie pop' restores) first
+  'homie stack clear' drops all entries`,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var entries []storage.StackEntry
+			err := withRepository(func(db *storage.Repository) error {
+				var err error
+				entries, err = db.Stack()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+
+			rows := make([][]string, 0, len(entries))
+			for i, entry := range entries {
+				rows = append(rows, []string{strconv.Itoa(i + 1), entry.PushedAt.Local().Format(time.DateTime), entry.Text})
+			}
+			if err = printTextTable([]string{"DEPTH", "PUSHED", "TEXT"}, rows, stackListWidth); err != nil {
+				log.Logger().Fatalf("failed to print stack: %v", err)
+			}
+		},
+	}
+
+	stackClearCmd = &cobra.Command{
+		Use:                   "clear",
+		Short:                 "Empty the clipboard stack",
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			var cleared int64
+			err := withRepository(func(db *storage.Repository) error {
+				var err error
+				cleared, err = db.ClearStack()
+				return err
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+			if log.Verbose() {
+				log.Logger().Printf("dropped %d stack entries\n", cleared)
+			}
+		},
+	}
+)
+
+func init() {
+	popCmd.Flags().Bool(
+		"print",
+		false,
+		"Print the entry instead of writing it to the clipboard",
+	)
+
+	stackCmd.AddCommand(stackClearCmd)
+	rootCmd.AddCommand(pushCmd, popCmd, stackCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kaliv0/homie/internal/log"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+var (
+	tagCmd = &cobra.Command{
+		Use:   "tag <id> <tag>...",
+		Short: "Tag a clipboard history item",
+		Long: `Label a clipboard history item, e.g. with prod, staging or ticket-123
+  Tags are lowercased, commas separate several. Find tagged items with 'homie history tag:prod';
+  the history window shows them as #prod and its fuzzy search matches them.`,
+		Example: `  homie tag 42 prod ticket-123
+  homie history tag:prod`,
+		Args:                  cobra.MinimumNArgs(2),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			id := parseItemID(args[0])
+			err := withRepository(func(db *storage.Repository) error {
+				return db.TagItem(id, args[1:]...)
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	untagCmd = &cobra.Command{
+		Use:                   "untag <id> [tag...]",
+		Short:                 "Remove tags from a clipboard history item",
+		Long:                  "Remove tags from a clipboard history item, all of them when none are given",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			id := parseItemID(args[0])
+			err := withRepository(func(db *storage.Repository) error {
+				return db.UntagItem(id, args[1:]...)
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+
+	noteCmd = &cobra.Command{
+		Use:   "note <id> [text]",
+		Short: "Annotate a clipboard history item",
+		Long: `Attach a free-text note to a clipboard history item, replacing the previous one
+  Without text the note is printed, an empty text ("") removes it.
+  The history preview shows the note and the fuzzy search matches it.`,
+		Example: `  homie note 42 "staging db password, rotates monthly"
+  homie note 42`,
+		Args:                  cobra.RangeArgs(1, 2),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			id := parseItemID(args[0])
+			err := withRepository(func(db *storage.Repository) error {
+				if len(args) == 2 {
+					return db.SetNote(id, args[1])
+				}
+				item, err := db.Get(id)
+				if err != nil {
+					return err
+				}
+				if item.Note != "" {
+					fmt.Println(item.Note)
+				}
+				return nil
+			})
+			if err != nil {
+				log.Logger().Fatal(err)
+			}
+		},
+	}
+)
+
+// parseItemID parses the id of a history item given as argument.
+func parseItemID(arg string) int {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		log.Logger().Fatalf("invalid id %q: %v", arg, err)
+	}
+	return id
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd, untagCmd, noteCmd)
+}
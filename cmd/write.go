@@ -12,11 +12,20 @@ import (
 	"github.com/kaliv0/homie/internal/storage"
 )
 
-// used as a workaround to enable copying inside tmux session
+// also used as a workaround to enable copying inside tmux session
 var writeCmd = &cobra.Command{
-	Use:    "write",
-	Hidden: true,
+	Use:   "write",
+	Short: "Copy stdin to the clipboard",
+	Long: `Copy stdin to the clipboard and save it in history
+  The counterpart of 'homie paste', e.g. 'echo hello | homie write'
+  With --tag the item is labeled right away, e.g. 'kubectl config current-context | homie write --tag k8s'`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, _ []string) {
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			log.Logger().Fatalf("failed to get 'tag' flag: %v", err)
+		}
+
 		data, err := io.ReadAll(os.Stdin)
 		if err != nil {
 			log.Logger().Fatalf("failed to read stdin: %v", err)
@@ -34,7 +43,7 @@ var writeCmd = &cobra.Command{
 		if err != nil {
 			log.Logger().Fatal(err)
 		}
-		db, err := storage.NewRepository(dbPath)
+		db, err := storage.Open(dbPath)
 		if err != nil {
 			log.Logger().Fatal(err)
 		}
@@ -45,12 +54,14 @@ var writeCmd = &cobra.Command{
 			}
 		}()
 
-		if err := db.Write([]byte(text)); err != nil {
+		if err := db.WriteTagged([]byte(text), tags); err != nil {
 			log.Logger().Fatal(err)
 		}
 	},
 }
 
 func init() {
+	writeCmd.Flags().StringSliceP("tag", "t", nil, "Tag the item (repeat or separate by commas)")
+
 	rootCmd.AddCommand(writeCmd)
 }
@@ -0,0 +1,206 @@
+// Package action offers commands based on the detected content of a history item,
+// e.g. opening a URL in the browser or running a copied shell command.
+package action
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/join"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// Action is a command template offered for items of one content type.
+type Action struct {
+	Name        string
+	Description string
+	Type        content.Type
+	// Confirm asks before the command runs.
+	Confirm bool
+	// Shell marks commands that only make sense in the calling shell (e.g. cd);
+	// they are printed for the shell to evaluate instead of being run.
+	Shell bool
+	// applies further restricts the items, e.g. to existing directories.
+	applies func(Data) bool
+	tmpl    *template.Template
+}
+
+// Data is the template input of a command.
+type Data struct {
+	ID   int
+	Text string
+	Type content.Type
+	// Path is Text with a leading ~/ expanded (paths only).
+	Path string
+}
+
+// NewData returns the template input for item.
+func NewData(item storage.ClipboardItem) Data {
+	d := Data{ID: item.ID, Text: strings.TrimSpace(item.ClipText), Type: item.Type()}
+	if d.Type == content.TypePath {
+		d.Path = expandHome(d.Text)
+	}
+	return d
+}
+
+func expandHome(p string) string {
+	rest, ok := strings.CutPrefix(p, "~/")
+	if !ok {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, rest)
+}
+
+var funcs = template.FuncMap{"quote": join.Quote}
+
+// imagePattern matches URLs of image files.
+var imagePattern = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|webp|svg|bmp|ico|avif)(\?.*)?$`)
+
+// builtins are offered unless a user action of the same type and name replaces them.
+var builtins = []Action{
+	{Name: "open", Type: content.TypeURL, Description: "open in the browser",
+		tmpl: mustParse("xdg-open {{quote .Text}}")},
+	{Name: "fetch", Type: content.TypeURL, Description: "download the image into the current directory",
+		applies: func(d Data) bool { return imagePattern.MatchString(d.Text) },
+		tmpl:    mustParse("curl -fL --remote-name {{quote .Text}}")},
+	{Name: "edit", Type: content.TypePath, Description: "open the file in $VISUAL/$EDITOR",
+		applies: isFile, tmpl: mustParse(`${VISUAL:-${EDITOR:-vi}} {{quote .Path}}`)},
+	{Name: "open", Type: content.TypePath, Description: "open with the default application or file manager",
+		applies: exists, tmpl: mustParse("xdg-open {{quote .Path}}")},
+	{Name: "cd", Type: content.TypePath, Description: "change into the directory (shell integration)",
+		Shell: true, applies: isDir, tmpl: mustParse("cd -- {{quote .Path}}")},
+	{Name: "run", Type: content.TypeCommand, Description: "run the command (asks first)",
+		Confirm: true, tmpl: mustParse("{{.Text}}")},
+}
+
+func mustParse(text string) *template.Template {
+	return template.Must(template.New("").Funcs(funcs).Parse(text))
+}
+
+func exists(d Data) bool {
+	_, err := os.Stat(d.Path)
+	return err == nil
+}
+
+func isFile(d Data) bool {
+	info, err := os.Stat(d.Path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func isDir(d Data) bool {
+	info, err := os.Stat(d.Path)
+	return err == nil && info.IsDir()
+}
+
+// CommandLine renders the shell command for d.
+func (a Action) CommandLine(d Data) (string, error) {
+	var b strings.Builder
+	if err := a.tmpl.Execute(&b, d); err != nil {
+		return "", fmt.Errorf("action %q: %w", a.Name, err)
+	}
+	return b.String(), nil
+}
+
+// Run executes the command for d through sh, attached to the current terminal except for its output,
+// which goes to stdout.
+func (a Action) Run(d Data, stdout io.Writer) error {
+	cmdLine, err := a.CommandLine(d)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", cmdLine)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("action %q (%s) failed: %w", a.Name, cmdLine, err)
+	}
+	return nil
+}
+
+// Catalog holds the built-in and user-defined actions.
+type Catalog struct {
+	actions []Action
+}
+
+// NewCatalog returns the built-ins with the user actions (content type -> name -> command template),
+// which replace built-ins of the same type and name and are otherwise added sorted by name.
+// User actions always ask for confirmation before running.
+func NewCatalog(templates map[string]map[string]string) (*Catalog, error) {
+	c := &Catalog{actions: slices.Clone(builtins)}
+	for _, kind := range sortedKeys(templates) {
+		if !slices.Contains(content.Types, content.Type(kind)) {
+			return nil, fmt.Errorf("actions for unknown content type %q", kind)
+		}
+		for _, name := range sortedKeys(templates[kind]) {
+			text := strings.TrimSpace(templates[kind][name])
+			if text == "" {
+				return nil, fmt.Errorf("action %q for %s has no command", name, kind)
+			}
+			tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template of action %q for %s: %w", name, kind, err)
+			}
+			a := Action{Name: name, Type: content.Type(kind), Description: "$ " + text, Confirm: true, tmpl: tmpl}
+			i := slices.IndexFunc(c.actions, func(b Action) bool { return b.Type == a.Type && b.Name == a.Name })
+			if i >= 0 {
+				c.actions[i] = a
+			} else {
+				c.actions = append(c.actions, a)
+			}
+		}
+	}
+	return c, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// For lists the actions applicable to d.
+func (c *Catalog) For(d Data) []Action {
+	var actions []Action
+	for _, a := range c.actions {
+		if a.Type == d.Type && (a.applies == nil || a.applies(d)) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// Lookup returns the action named name applicable to d.
+func (c *Catalog) Lookup(d Data, name string) (Action, error) {
+	actions := c.For(d)
+	i := slices.IndexFunc(actions, func(a Action) bool { return a.Name == name })
+	if i < 0 {
+		return Action{}, fmt.Errorf("no action %q for this %s item, choose between: %s", name, d.Type, Names(actions))
+	}
+	return actions[i], nil
+}
+
+// Names joins the names of actions for messages.
+func Names(actions []Action) string {
+	if len(actions) == 0 {
+		return "(none)"
+	}
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		names = append(names, a.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,128 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func catalog(t *testing.T, templates map[string]map[string]string) *Catalog {
+	t.Helper()
+	c, err := NewCatalog(templates)
+	if err != nil {
+		t.Fatalf("NewCatalog() failed: %v", err)
+	}
+	return c
+}
+
+func data(text string) Data {
+	return NewData(storage.ClipboardItem{ID: 7, ClipText: text})
+}
+
+func TestFor(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := catalog(t, nil)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"https://example.com/docs", "open"},
+		{"https://example.com/cat.PNG?size=2", "open, fetch"},
+		{file, "edit, open"},
+		{dir, "open, cd"},
+		{filepath.Join(dir, "missing.txt"), "(none)"},
+		{"ls -la /tmp | grep homie", "run"},
+		{"plain text", "(none)"},
+	}
+	for _, tt := range tests {
+		if got := Names(c.For(data(tt.text))); got != tt.want {
+			t.Errorf("For(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	c := catalog(t, nil)
+	dir := filepath.Join(t.TempDir(), "it's here")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	d := data(dir)
+	a, err := c.Lookup(d, "cd")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	got, err := a.CommandLine(d)
+	if err != nil {
+		t.Fatalf("CommandLine() failed: %v", err)
+	}
+	if want := "cd -- '" + strings.ReplaceAll(dir, "'", `'\''`) + "'"; got != want || !a.Shell {
+		t.Errorf("CommandLine() = %q (shell=%t), want %q", got, a.Shell, want)
+	}
+}
+
+func TestNewData_ExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	d := data("~/projects/homie\n")
+	if d.Type != content.TypePath || d.Path != filepath.Join(home, "projects/homie") || d.Text != "~/projects/homie" {
+		t.Errorf("unexpected data %+v", d)
+	}
+}
+
+func TestUserActions(t *testing.T) {
+	c := catalog(t, map[string]map[string]string{
+		"url": {"open": "firefox {{quote .Text}}", "archive": "echo archived {{.Text}}"},
+	})
+	d := data("https://example.com")
+	if got := Names(c.For(d)); got != "open, archive" {
+		t.Fatalf("expected the user open to replace the built-in, got %s", got)
+	}
+	a, err := c.Lookup(d, "open")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	if got, _ := a.CommandLine(d); got != "firefox https://example.com" || !a.Confirm {
+		t.Errorf("unexpected user action %q (confirm=%t)", got, a.Confirm)
+	}
+	if _, err = c.Lookup(d, "cd"); err == nil || !strings.Contains(err.Error(), "open, archive") {
+		t.Errorf("expected an error listing the actions, got %v", err)
+	}
+}
+
+func TestNewCatalog_Errors(t *testing.T) {
+	for _, templates := range []map[string]map[string]string{
+		{"links": {"open": "xdg-open {{.Text}}"}},
+		{"url": {"open": " "}},
+		{"url": {"open": "xdg-open {{.Text"}},
+	} {
+		if _, err := NewCatalog(templates); err == nil {
+			t.Errorf("expected NewCatalog(%v) to fail", templates)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := catalog(t, map[string]map[string]string{"number": {"double": "echo $(({{.Text}} * 2))"}})
+	d := data("21")
+	a, err := c.Lookup(d, "double")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	var out strings.Builder
+	if err = a.Run(d, &out); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if out.String() != "42\n" {
+		t.Errorf("unexpected command output %q", out.String())
+	}
+}
@@ -2,6 +2,7 @@ package clipboard
 
 import (
 	"context"
+	"os"
 
 	gclip "golang.design/x/clipboard"
 )
@@ -12,7 +13,9 @@ type Writer interface {
 }
 
 // TrackClipboard watches for clipboard text changes and persists them.
-func TrackClipboard(ctx context.Context, w Writer, changes <-chan gclip.Data) error {
+// A signal on reload runs onReload in the same loop, so a config reload never overlaps a write
+// (viper, which both read, isn't safe for concurrent use). A nil reload channel never fires.
+func TrackClipboard(ctx context.Context, w Writer, changes <-chan gclip.Data, reload <-chan os.Signal, onReload func()) error {
 	for {
 		select {
 		case item, ok := <-changes:
@@ -22,6 +25,8 @@ func TrackClipboard(ctx context.Context, w Writer, changes <-chan gclip.Data) er
 			if err := w.Write(item.Bytes); err != nil {
 				return err
 			}
+		case <-reload:
+			onReload()
 		case <-ctx.Done():
 			return nil
 		}
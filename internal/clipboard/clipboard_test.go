@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -47,7 +49,7 @@ func trackClosed(t *testing.T, writer Writer, items ...gclip.Data) error {
 	}
 	close(ch)
 
-	return TrackClipboard(t.Context(), writer, ch)
+	return TrackClipboard(t.Context(), writer, ch, nil, nil)
 }
 
 // assertTrackClipboardDone waits for TrackClipboard to finish and expects a nil error.
@@ -96,7 +98,7 @@ func TestTrackClipboard_ContextCancellation(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- TrackClipboard(ctx, writer, ch)
+		done <- TrackClipboard(ctx, writer, ch, nil, nil)
 	}()
 
 	cancel()
@@ -111,7 +113,7 @@ func TestTrackClipboard_ChannelClose(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- TrackClipboard(t.Context(), writer, ch)
+		done <- TrackClipboard(t.Context(), writer, ch, nil, nil)
 	}()
 
 	close(ch)
@@ -200,7 +202,7 @@ func TestTrackClipboard_ContextCancelDuringItems(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- TrackClipboard(ctx, writer, ch)
+		done <- TrackClipboard(ctx, writer, ch, nil, nil)
 	}()
 
 	ch <- gclip.Data{Format: gclip.FmtText, Bytes: []byte("before-cancel")}
@@ -212,3 +214,25 @@ func TestTrackClipboard_ContextCancelDuringItems(t *testing.T) {
 		t.Errorf("expected 1 item processed before cancel, got %d", len(writer.items))
 	}
 }
+
+func TestTrackClipboard_Reload(t *testing.T) {
+	t.Parallel()
+	ch := make(chan gclip.Data)
+	reload := make(chan os.Signal)
+	writer := &mockWriter{}
+
+	// onReload runs on the tracking loop, so it sees every write before it without locking
+	reloaded := make(chan int, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- TrackClipboard(t.Context(), writer, ch, reload, func() { reloaded <- len(writer.items) })
+	}()
+
+	ch <- gclip.Data{Format: gclip.FmtText, Bytes: []byte("before-reload")}
+	reload <- syscall.SIGUSR1
+	if n := <-reloaded; n != 1 {
+		t.Errorf("expected the reload after 1 write, got %d", n)
+	}
+	close(ch)
+	assertTrackClipboardDone(t, done, "TrackClipboard did not return after channel close")
+}
@@ -1,6 +1,7 @@
 package clipboard
 
 import (
+	"bytes"
 	"fmt"
 	"os/exec"
 )
@@ -52,3 +53,29 @@ func Write(text, tool string) error {
 	}
 	return nil
 }
+
+// Read returns the clipboard text using the specified tool.
+func Read(tool string) (string, error) {
+	var cmdName string
+	var args []string
+	switch tool {
+	case "xclip":
+		cmdName, args = "xclip", []string{"-out", "-selection", "clipboard"}
+	case "xsel":
+		cmdName, args = "xsel", []string{"--output", "--clipboard"}
+	case "wl-clipboard":
+		cmdName, args = "wl-paste", []string{"--no-newline"}
+	default:
+		return "", fmt.Errorf("unsupported clipboard tool: %q", tool)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(cmdName, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("clip command failed during read (cmd=%s %v): %w: %s",
+			cmdName, args, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return string(out), nil
+}
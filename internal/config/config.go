@@ -1,12 +1,14 @@
 package config
 
 import (
-	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -16,89 +18,346 @@ const (
 	ViperKeyVerbose = "verbose"
 	ViperKeyLogFile = "log_file"
 	ViperKeyPIDFile = "pid_file"
+	ViperKeyProfile = "profile"
+	ViperKeyDBPath  = "db_path"
+
+	// ViperKeyConfigFile is bound to the --config flag.
+	ViperKeyConfigFile = "config"
+
+	// ViperKeyProfiles holds per-profile sections overriding the top-level keys.
+	ViperKeyProfiles = "profiles"
+	// ViperKeyTransforms maps user-defined transform names to shell commands.
+	ViperKeyTransforms = "transforms"
+	// ViperKeyActions maps content types to user-defined actions (name -> command template).
+	ViperKeyActions = "actions"
 )
 
+// DefaultProfile is used when neither --profile nor HOMIE_PROFILE is set.
+const DefaultProfile = "default"
+
 const (
 	xdgConf       = "XDG_CONFIG_HOME"
 	xdgRuntime    = "XDG_RUNTIME_DIR"
 	runDir        = "/run/user"
-	appConfPath   = "$HOME/"
 	homeDirPrefix = "~/"
 
-	dbConfDirPerm = 0755
+	dbConfDirPerm = 0o700
 	dbConfDirName = ".config"
 	dbSubdirName  = "homie"
 	dbFileName    = "homie.db"
 
 	pidFileName = "homie.pid"
 
-	confFileName = ".homierc"
-	confFileType = "yaml"
+	snippetsDirName = "snippets"
+
+	cycleStateSuffix = ".cycle"
+
+	confFileName    = ".homierc"
+	xdgConfFileName = "config.yaml"
+	confFileType    = "yaml"
 )
 
-// ReadConfig loads configuration from ~/.homierc once.
+// profileNamePattern is lowercase only: viper lowercases the keys of the profiles section,
+// so a mixed-case name would map to a different section and pidfile than the one it was started with.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// transformNamePattern (also used for action names) is lowercase only, since viper lowercases map keys.
+var transformNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// profilePathKeys are resolved per profile by the path helpers instead of being merged from the
+// profile section, so that a shared value can be suffixed with the profile name.
+var profilePathKeys = []string{ViperKeyLogFile, ViperKeyPIDFile, ViperKeyDBPath}
+
+// ReadConfig loads configuration once from the --config file,
+// $XDG_CONFIG_HOME/homie/config.yaml or ~/.homierc (first one found).
 var ReadConfig = sync.OnceValue(readConfig)
 
+// Precedence (highest first): explicit flag, HOMIE_* environment variable, config file, default.
 func readConfig() error {
-	viper.SetDefault(ViperKeyVerbose, false)
-	viper.SetDefault(ViperKeyLogFile, "")
-	viper.SetDefault(ViperKeyPIDFile, "")
+	for _, k := range Keys {
+		viper.SetDefault(k.Name, k.Default)
+	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
+	viper.AutomaticEnv()
 
-	viper.SetConfigName(confFileName)
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
 	viper.SetConfigType(confFileType)
-	viper.AddConfigPath(appConfPath)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := errors.AsType[viper.ConfigFileNotFoundError](err); !ok {
-			return fmt.Errorf("failed to read config file %s from %s: %w", confFileName, appConfPath, err)
+	if path == "" {
+		// no config file (any more, on a reload) -> drop the values of the file read before
+		if err = viper.ReadConfig(strings.NewReader("")); err != nil {
+			return fmt.Errorf("failed to reset config: %w", err)
 		}
+		return applyProfile()
+	}
+	viper.SetConfigFile(path)
+	if err = viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return applyProfile()
+}
+
+// Reload re-reads the config file, e.g. in the running daemon.
+// An invalid file is rejected and the previously loaded values stay in effect; a removed file restores the defaults.
+// db_path and pid_file are only picked up on restart.
+func Reload() error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		problems, err := ValidateFile(path)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			msgs := make([]string, 0, len(problems))
+			for _, p := range problems {
+				msgs = append(msgs, p.String())
+			}
+			return fmt.Errorf("invalid config file %s: %s", path, strings.Join(msgs, "; "))
+		}
+	}
+	return readConfig()
+}
+
+// configFilePath returns the --config file or the first existing default config file.
+// An empty path means no config file is present.
+func configFilePath() (string, error) {
+	if p := ExpandHomePath(strings.TrimSpace(viper.GetString(ViperKeyConfigFile))); p != "" {
+		if _, err := os.Stat(p); err != nil {
+			return "", fmt.Errorf("failed to open config file %q: %w", p, err)
+		}
+		return p, nil
+	}
+
+	for _, p := range configFileCandidates() {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// configFileCandidates lists the default config files in lookup order.
+func configFileCandidates() []string {
+	var paths []string
+	if dir, err := appConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, xdgConfFileName))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(homeDir, confFileName))
+	}
+	return paths
+}
+
+// appConfigDir returns $XDG_CONFIG_HOME/homie (or ~/.config/homie) without creating it.
+func appConfigDir() (string, error) {
+	if xdgHome := os.Getenv(xdgConf); xdgHome != "" {
+		return filepath.Join(xdgHome, dbSubdirName), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, dbConfDirName, dbSubdirName), nil
+}
+
+// applyProfile merges the active profile section of .homierc over the top-level keys.
+func applyProfile() error {
+	profile := Profile()
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' and '_'", profile)
+	}
+	if profile == DefaultProfile {
+		return nil
+	}
+
+	section := viper.GetStringMap(profileKey(profile, ""))
+	overrides := maps.Clone(section)
+	for _, key := range profilePathKeys {
+		delete(overrides, key)
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+	if err := viper.MergeConfigMap(overrides); err != nil {
+		return fmt.Errorf("failed to apply profile %q: %w", profile, err)
 	}
 	return nil
 }
 
+// Profile returns the active profile name (--profile, HOMIE_PROFILE or DefaultProfile).
+func Profile() string {
+	if p := strings.TrimSpace(viper.GetString(ViperKeyProfile)); p != "" {
+		return p
+	}
+	return DefaultProfile
+}
+
+// ProfileFileName suffixes name with the profile, e.g. homie.db -> homie-work.db.
+// The default profile keeps the name unchanged.
+func ProfileFileName(name, profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + profile + ext
+}
+
+// profileKey returns the viper key of key inside the profile section (or the section itself).
+func profileKey(profile, key string) string {
+	section := ViperKeyProfiles + "." + profile
+	if key == "" {
+		return section
+	}
+	return section + "." + key
+}
+
+// profileSetting resolves a path-like key for profile: the profile section wins,
+// otherwise the top-level value is suffixed with the profile name.
+func profileSetting(profile, key string) string {
+	if profile != DefaultProfile {
+		if p := strings.TrimSpace(viper.GetString(profileKey(profile, key))); p != "" {
+			return p
+		}
+	}
+	p := strings.TrimSpace(viper.GetString(key))
+	if p == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(p), ProfileFileName(filepath.Base(p), profile))
+}
+
+// LogFilePath returns the log file of the active profile (empty when logging to stderr).
+func LogFilePath() string {
+	return ExpandHomePath(profileSetting(Profile(), ViperKeyLogFile))
+}
+
 var (
 	once    sync.Once
 	dbPath  string
 	pathErr error
 )
 
-// DBPath returns the absolute path to the SQLite database file.
+// DBPath returns the absolute path to the SQLite database file
+// (db_path from the config, else homie.db under $XDG_CONFIG_HOME/homie or ~/.config/homie).
 func DBPath() (string, error) {
 	once.Do(func() {
-		var subDirsList []string
-		if xdgHome := os.Getenv(xdgConf); xdgHome != "" {
-			subDirsList = append(subDirsList, xdgHome)
-		} else {
-			homeDir, err := os.UserHomeDir()
+		if p := ExpandHomePath(profileSetting(Profile(), ViperKeyDBPath)); p != "" {
+			absPath, err := filepath.Abs(p)
 			if err != nil {
-				pathErr = fmt.Errorf("failed to get user home directory: %w", err)
+				pathErr = fmt.Errorf("failed to resolve db_path %q: %w", p, err)
 				return
 			}
-			subDirsList = append(subDirsList, homeDir, dbConfDirName)
+			if err = os.MkdirAll(filepath.Dir(absPath), dbConfDirPerm); err != nil {
+				pathErr = fmt.Errorf("failed to create database directory %q: %w", filepath.Dir(absPath), err)
+				return
+			}
+			dbPath = absPath
+			return
 		}
-		subDirsList = append(subDirsList, dbSubdirName)
-		configDir := filepath.Join(subDirsList...)
-		if err := os.MkdirAll(configDir, dbConfDirPerm); err != nil {
+
+		configDir, err := appConfigDir()
+		if err != nil {
+			pathErr = err
+			return
+		}
+		if err = os.MkdirAll(configDir, dbConfDirPerm); err != nil {
 			pathErr = fmt.Errorf("failed to create config directory %q: %w", configDir, err)
 			return
 		}
-		dbPath = filepath.Join(configDir, dbFileName)
+		dbPath = filepath.Join(configDir, ProfileFileName(dbFileName, Profile()))
 	})
 	return dbPath, pathErr
 }
 
+// SnippetsDir returns the directory of snippet files (snippets_dir from the config,
+// else snippets under $XDG_CONFIG_HOME/homie or ~/.config/homie). It may not exist.
+func SnippetsDir() (string, error) {
+	if p := ExpandHomePath(strings.TrimSpace(viper.GetString(ViperKeySnippetsDir))); p != "" {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s %q: %w", ViperKeySnippetsDir, p, err)
+		}
+		return absPath, nil
+	}
+	configDir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, snippetsDirName), nil
+}
+
+// CycleStatePath returns the state file of 'homie cycle', which sits next to the database it indexes.
+func CycleStatePath() (string, error) {
+	dbPath, err := DBPath()
+	if err != nil {
+		return "", err
+	}
+	return dbPath + cycleStateSuffix, nil
+}
+
+// CycleTimeout returns how long 'homie cycle' keeps its position between two calls.
+func CycleTimeout() time.Duration {
+	return time.Duration(max(viper.GetInt(ViperKeyCycleTimeout), 1)) * time.Second
+}
+
+// FileUsed returns the config file that was loaded (empty when none was found).
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
 // PIDFilePath returns the path to the daemon pidfile.
 func PIDFilePath() (string, error) {
 	if err := ReadConfig(); err != nil {
 		return "", err
 	}
-	if p := ExpandHomePath(strings.TrimSpace(viper.GetString(ViperKeyPIDFile))); p != "" {
-		return p, nil
+	return pidFilePath(Profile()), nil
+}
+
+// ProfilePIDFiles maps every known profile to its pidfile: the default profile,
+// profiles declared in .homierc and profiles whose pidfile sits next to the default one.
+func ProfilePIDFiles() (map[string]string, error) {
+	if err := ReadConfig(); err != nil {
+		return nil, err
+	}
+
+	base := pidFilePath(DefaultProfile)
+	files := map[string]string{DefaultProfile: base}
+
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(filepath.Base(base), ext) + "-"
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(base), prefix+"*"+ext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pidfiles next to %q: %w", base, err)
+	}
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), prefix), ext)
+		if profileNamePattern.MatchString(name) {
+			files[name] = m
+		}
 	}
 
+	for name := range viper.GetStringMap(ViperKeyProfiles) {
+		files[name] = pidFilePath(name)
+	}
+	return files, nil
+}
+
+func pidFilePath(profile string) string {
+	if p := ExpandHomePath(profileSetting(profile, ViperKeyPIDFile)); p != "" {
+		return p
+	}
+
+	name := ProfileFileName(pidFileName, profile)
 	if xdg := os.Getenv(xdgRuntime); xdg != "" {
-		return filepath.Join(xdg, pidFileName), nil
+		return filepath.Join(xdg, name)
 	}
-	return filepath.Join(runDir, fmt.Sprintf("%d", os.Getuid()), pidFileName), nil
+	return filepath.Join(runDir, fmt.Sprintf("%d", os.Getuid()), name)
 }
 
 // PreparePIDFile returns the pidfile path and ensures its parent directory exists.
@@ -123,3 +382,17 @@ func ExpandHomePath(p string) string {
 	}
 	return filepath.Join(homeDir, strings.TrimPrefix(p, homeDirPrefix))
 }
+
+// Transforms returns the user-defined transforms (name -> shell command) from the config.
+func Transforms() map[string]string {
+	return viper.GetStringMapString(ViperKeyTransforms)
+}
+
+// Actions returns the user-defined actions from the config: content type -> action name -> command template.
+func Actions() map[string]map[string]string {
+	actions := make(map[string]map[string]string)
+	for kind := range viper.GetStringMap(ViperKeyActions) {
+		actions[kind] = viper.GetStringMapString(ViperKeyActions + "." + kind)
+	}
+	return actions
+}
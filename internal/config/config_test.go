@@ -6,6 +6,8 @@ import (
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 // resetDBPath resets the sync.Once state and sets the XDG env var for DBPath tests.
@@ -229,3 +231,413 @@ func TestReadConfig_WithInvalidYAML(t *testing.T) {
 	// Should not panic regardless of parse result
 	_ = ReadConfig()
 }
+
+// useProfile activates profile for the duration of the test.
+func useProfile(t *testing.T, profile string) {
+	t.Helper()
+	viper.Set(ViperKeyProfile, profile)
+	t.Cleanup(func() { viper.Set(ViperKeyProfile, "") })
+}
+
+func TestProfileFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		profile string
+		want    string
+	}{
+		{"default profile unchanged", "homie.db", DefaultProfile, "homie.db"},
+		{"empty profile unchanged", "homie.pid", "", "homie.pid"},
+		{"named profile suffixed", "homie.db", "work", "homie-work.db"},
+		{"no extension", "homie", "work", "homie-work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileFileName(tt.file, tt.profile); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProfile_FromEnv(t *testing.T) {
+	useLiveReadConfig(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HOMIE_PROFILE", "personal")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := Profile(); got != "personal" {
+		t.Errorf("expected profile=%q, got %q", "personal", got)
+	}
+}
+
+func TestReadConfig_InvalidProfile(t *testing.T) {
+	useLiveReadConfig(t)
+	t.Setenv("HOME", t.TempDir())
+	useProfile(t, "../work")
+
+	if err := ReadConfig(); err == nil {
+		t.Fatal("expected error for invalid profile name, got nil")
+	}
+}
+
+func TestReadConfig_MixedCaseProfile(t *testing.T) {
+	useLiveReadConfig(t)
+	t.Setenv("HOME", t.TempDir())
+	useProfile(t, "Work")
+
+	if err := ReadConfig(); err == nil {
+		t.Fatal("expected error for a mixed-case profile name, got nil")
+	}
+}
+
+func TestDBPath_WithProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	resetDBPath(t, tmpDir)
+	useProfile(t, "work")
+
+	path := mustDBPath(t)
+
+	expected := filepath.Join(tmpDir, dbSubdirName, "homie-work.db")
+	if path != expected {
+		t.Errorf("expected path=%q, got %q", expected, path)
+	}
+}
+
+func TestReadConfig_ProfileSectionOverridesTopLevel(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	configContent := []byte("limit: 15\nttl: 7\nprofiles:\n  work:\n    limit: 50\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, confFileName), configContent, 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	useProfile(t, "work")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt("limit"); got != 50 {
+		t.Errorf("expected profile limit=50, got %d", got)
+	}
+	if got := viper.GetInt("ttl"); got != 7 {
+		t.Errorf("expected inherited ttl=7, got %d", got)
+	}
+}
+
+func TestPIDFilePath_ProfileSuffixesDefault(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgRuntime, tmpDir)
+	useProfile(t, "work")
+
+	got, err := PIDFilePath()
+	if err != nil {
+		t.Fatalf("PIDFilePath() failed: %v", err)
+	}
+	if expected := filepath.Join(tmpDir, "homie-work.pid"); got != expected {
+		t.Errorf("expected path=%q, got %q", expected, got)
+	}
+}
+
+func TestLogFilePath_Profile(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	shared := filepath.Join(tmpDir, "homie.log")
+	own := filepath.Join(tmpDir, "personal.log")
+	configContent := []byte("log_file: " + shared + "\nprofiles:\n  personal:\n    log_file: " + own + "\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, confFileName), configContent, 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{DefaultProfile, shared},
+		{"work", filepath.Join(tmpDir, "homie-work.log")},
+		{"personal", own},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			useProfile(t, tt.profile)
+			if err := ReadConfig(); err != nil {
+				t.Fatalf("ReadConfig() failed: %v", err)
+			}
+			if got := LogFilePath(); got != tt.want {
+				t.Errorf("expected log file=%q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProfilePIDFiles(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgRuntime, tmpDir)
+	configContent := []byte("profiles:\n  work:\n    limit: 5\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, confFileName), configContent, 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "homie-adhoc.pid"), nil, 0600); err != nil {
+		t.Fatalf("failed to write pidfile: %v", err)
+	}
+
+	files, err := ProfilePIDFiles()
+	if err != nil {
+		t.Fatalf("ProfilePIDFiles() failed: %v", err)
+	}
+
+	want := map[string]string{
+		DefaultProfile: filepath.Join(tmpDir, "homie.pid"),
+		"work":         filepath.Join(tmpDir, "homie-work.pid"),
+		"adhoc":        filepath.Join(tmpDir, "homie-adhoc.pid"),
+	}
+	for profile, path := range want {
+		if files[profile] != path {
+			t.Errorf("profile %q: expected pidfile=%q, got %q", profile, path, files[profile])
+		}
+	}
+}
+
+// writeConfigFile writes content to path, creating parent directories.
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestReadConfig_PrefersXDGConfig(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	xdgDir := filepath.Join(tmpDir, "xdg")
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, xdgDir)
+	writeConfigFile(t, filepath.Join(xdgDir, dbSubdirName, xdgConfFileName), "limit: 33\n")
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "limit: 44\n")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt("limit"); got != 33 {
+		t.Errorf("expected limit from XDG config=33, got %d", got)
+	}
+	if got, want := FileUsed(), filepath.Join(xdgDir, dbSubdirName, xdgConfFileName); got != want {
+		t.Errorf("expected config file=%q, got %q", want, got)
+	}
+}
+
+func TestReadConfig_FallsBackToHomierc(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "empty-xdg"))
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "limit: 44\n")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt("limit"); got != 44 {
+		t.Errorf("expected limit from .homierc=44, got %d", got)
+	}
+}
+
+func TestReadConfig_ExplicitConfigFile(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	path := filepath.Join(tmpDir, "custom", "homie.yaml")
+	writeConfigFile(t, path, "limit: 55\n")
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "limit: 44\n")
+	viper.Set(ViperKeyConfigFile, path)
+	t.Cleanup(func() { viper.Set(ViperKeyConfigFile, "") })
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt("limit"); got != 55 {
+		t.Errorf("expected limit from --config file=55, got %d", got)
+	}
+}
+
+func TestReadConfig_MissingExplicitConfigFile(t *testing.T) {
+	useLiveReadConfig(t)
+
+	t.Setenv("HOME", t.TempDir())
+	viper.Set(ViperKeyConfigFile, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Cleanup(func() { viper.Set(ViperKeyConfigFile, "") })
+
+	if err := ReadConfig(); err == nil {
+		t.Fatal("expected error for missing --config file, got nil")
+	}
+}
+
+func TestDBPath_ConfigOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	resetDBPath(t, tmpDir)
+	path := filepath.Join(tmpDir, "vault", "clips.db")
+	viper.Set(ViperKeyDBPath, path)
+	t.Cleanup(func() { viper.Set(ViperKeyDBPath, "") })
+
+	if got := mustDBPath(t); got != path {
+		t.Errorf("expected path=%q, got %q", path, got)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("expected database directory to be created: %v", err)
+	}
+	if info.Mode().Perm() != dbConfDirPerm {
+		t.Errorf("expected permissions %o, got %o", dbConfDirPerm, info.Mode().Perm())
+	}
+}
+
+func TestDBPath_ConfigOverrideWithProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	resetDBPath(t, tmpDir)
+	viper.Set(ViperKeyDBPath, filepath.Join(tmpDir, "clips.db"))
+	t.Cleanup(func() { viper.Set(ViperKeyDBPath, "") })
+	useProfile(t, "work")
+
+	if got, want := mustDBPath(t), filepath.Join(tmpDir, "clips-work.db"); got != want {
+		t.Errorf("expected path=%q, got %q", want, got)
+	}
+}
+
+func TestReload_AppliesValidConfig(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	path := filepath.Join(tmpDir, confFileName)
+	writeConfigFile(t, path, "limit: 10\n")
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+
+	writeConfigFile(t, path, "limit: 25\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	if got := viper.GetInt(ViperKeyLimit); got != 25 {
+		t.Errorf("expected reloaded limit=25, got %d", got)
+	}
+}
+
+func TestReload_RejectsInvalidConfig(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	path := filepath.Join(tmpDir, confFileName)
+	writeConfigFile(t, path, "limit: 10\nttl: 3\n")
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+
+	writeConfigFile(t, path, "limit: 0\nttl: 9\n")
+	if err := Reload(); err == nil {
+		t.Fatal("expected error for invalid config, got nil")
+	}
+	if got := viper.GetInt(ViperKeyLimit); got != 10 {
+		t.Errorf("expected previous limit=10 to stay, got %d", got)
+	}
+	if got := viper.GetInt(ViperKeyTTL); got != 3 {
+		t.Errorf("expected previous ttl=3 to stay, got %d", got)
+	}
+}
+
+func TestReload_RemovedConfigRestoresDefaults(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	path := filepath.Join(tmpDir, confFileName)
+	writeConfigFile(t, path, "limit: 10\n")
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	if got := viper.GetInt(ViperKeyLimit); got != 20 {
+		t.Errorf("expected the default limit=20 after removing the file, got %d", got)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "transforms:\n  rot13: tr A-Za-z N-ZA-Mn-za-m\n")
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	t.Cleanup(func() { viper.Set(ViperKeyTransforms, nil) })
+
+	if got := Transforms(); len(got) != 1 || got["rot13"] != "tr A-Za-z N-ZA-Mn-za-m" {
+		t.Errorf("unexpected transforms %v", got)
+	}
+}
+
+func TestActions(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "actions:\n  ip:\n    ping: ping -c 3 {{.Text}}\n")
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	t.Cleanup(func() { viper.Set(ViperKeyActions, nil) })
+
+	if got := Actions(); len(got) != 1 || got["ip"]["ping"] != "ping -c 3 {{.Text}}" {
+		t.Errorf("unexpected actions %v", got)
+	}
+}
+
+func TestSnippetsDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv(xdgConf, tmpDir)
+	t.Setenv("HOME", tmpDir)
+
+	dir, err := SnippetsDir()
+	if err != nil {
+		t.Fatalf("SnippetsDir() failed: %v", err)
+	}
+	if want := filepath.Join(tmpDir, dbSubdirName, snippetsDirName); dir != want {
+		t.Errorf("expected default dir %q, got %q", want, dir)
+	}
+
+	viper.Set(ViperKeySnippetsDir, "~/dotfiles/snippets")
+	t.Cleanup(func() { viper.Set(ViperKeySnippetsDir, nil) })
+	if dir, err = SnippetsDir(); err != nil || dir != filepath.Join(tmpDir, "dotfiles/snippets") {
+		t.Errorf("expected the configured dir with ~ expanded, got %q, %v", dir, err)
+	}
+}
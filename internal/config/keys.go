@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Viper keys of settings read outside this package.
+const (
+	ViperKeyLimit         = "limit"
+	ViperKeyMaxSize       = "max_size"
+	ViperKeyTTL           = "ttl"
+	ViperKeyCleanUp       = "clean_up"
+	ViperKeyClipboardTool = "clipboard_tool"
+	ViperKeyTheme         = "theme"
+	ViperKeyJoinSeparator = "join_separator"
+	ViperKeyJoinOrder     = "join_order"
+	ViperKeyJoinTemplate  = "join_template"
+	ViperKeyJoinQuote     = "join_quote"
+	ViperKeySnippetsDir   = "snippets_dir"
+	ViperKeyCycleTimeout  = "cycle_timeout"
+)
+
+const envPrefix = "HOMIE"
+
+// Kind is the value type of a config key.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// Key describes a supported .homierc key.
+type Key struct {
+	Name    string
+	Kind    Kind
+	Default any
+	Usage   string
+	// Flag names the flag overriding the key, global or of 'homie history' (empty if none).
+	Flag string
+	// Min is the lowest accepted value of an int key.
+	Min int
+	// Choices restricts a non-empty string value to the listed ones.
+	Choices []string
+}
+
+// Keys lists every supported top-level key.
+var Keys = []Key{
+	{Name: ViperKeyLimit, Kind: KindInt, Default: 20, Min: 1,
+		Usage: "history page size and minimum amount of unpinned items kept after clean_up", Flag: "limit"},
+	{Name: ViperKeyMaxSize, Kind: KindInt, Default: 500, Min: 1, Usage: "maximum amount of stored records"},
+	{Name: ViperKeyTTL, Kind: KindInt, Default: 0, Usage: "retention period in days (0 -> use max_size)"},
+	{Name: ViperKeyCleanUp, Kind: KindBool, Default: false, Usage: "trim history when the daemon starts"},
+	{Name: ViperKeyClipboardTool, Kind: KindString, Default: "", Usage: "xclip, xsel or wl-clipboard (overrides use_*)",
+		Choices: []string{"xclip", "xsel", "wl-clipboard"}},
+	{Name: ViperKeyTheme, Kind: KindString, Default: "dark", Usage: "preview color theme (NO_COLOR disables colors)",
+		Choices: []string{"dark", "light", "none"}},
+	{Name: ViperKeyJoinSeparator, Kind: KindString, Default: " ",
+		Usage: `separator between multi-selected items (\n, \t, \0 escapes)`, Flag: "separator"},
+	{Name: ViperKeyJoinOrder, Kind: KindString, Default: "selection", Usage: "order of multi-selected items",
+		Choices: []string{"selection", "chronological", "reverse"}, Flag: "order"},
+	{Name: ViperKeyJoinTemplate, Kind: KindString, Default: "", Usage: `template per selected item, e.g. "{{.Text}}"`,
+		Flag: "template"},
+	{Name: ViperKeyJoinQuote, Kind: KindBool, Default: false, Usage: "shell-quote each selected item", Flag: "quote"},
+	{Name: ViperKeySnippetsDir, Kind: KindString, Default: "",
+		Usage: "directory of YAML/TOML snippet files (default -> $XDG_CONFIG_HOME/homie/snippets)"},
+	{Name: ViperKeyCycleTimeout, Kind: KindInt, Default: 10, Min: 1,
+		Usage: "seconds after which homie cycle starts again from the newest item"},
+	{Name: "use_xclip", Kind: KindBool, Default: false, Usage: "use xclip for clipboard text management on linux"},
+	{Name: "use_xsel", Kind: KindBool, Default: false, Usage: "use xsel"},
+	{Name: "use_wl-clipboard", Kind: KindBool, Default: false, Usage: "use wl-clipboard"},
+	{Name: ViperKeyVerbose, Kind: KindBool, Default: false, Usage: "verbose diagnostic messages", Flag: "verbose"},
+	{Name: ViperKeyLogFile, Kind: KindString, Default: "", Usage: "append logs to this file", Flag: "log-file"},
+	{Name: ViperKeyPIDFile, Kind: KindString, Default: "", Usage: "daemon pidfile"},
+	{Name: ViperKeyDBPath, Kind: KindString, Default: "", Usage: "database file"},
+	{Name: ViperKeyProfile, Kind: KindString, Default: "", Usage: "active profile", Flag: "profile"},
+}
+
+// LookupKey returns the registered key with the given name.
+func LookupKey(name string) (Key, bool) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// EnvName returns the environment variable overriding key, e.g. use_wl-clipboard -> HOMIE_USE_WL_CLIPBOARD.
+func EnvName(key string) string {
+	return envPrefix + "_" + envKeyReplacer.Replace(strings.ToUpper(key))
+}
+
+// LookupEnv returns the value of the environment variable overriding key.
+func LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(EnvName(key))
+}
+
+var envKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
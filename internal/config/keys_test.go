@@ -0,0 +1,71 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"limit", "HOMIE_LIMIT"},
+		{"clipboard_tool", "HOMIE_CLIPBOARD_TOOL"},
+		{"use_wl-clipboard", "HOMIE_USE_WL_CLIPBOARD"},
+		{"log_file", "HOMIE_LOG_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := EnvName(tt.key); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKeys_UniqueNames(t *testing.T) {
+	seen := make(map[string]bool, len(Keys))
+	for _, k := range Keys {
+		if seen[k.Name] {
+			t.Errorf("duplicate key %q", k.Name)
+		}
+		seen[k.Name] = true
+	}
+}
+
+func TestReadConfig_EnvOverridesFile(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "limit: 44\nuse_wl-clipboard: false\n")
+	t.Setenv("HOMIE_LIMIT", "12")
+	t.Setenv("HOMIE_USE_WL_CLIPBOARD", "true")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt(ViperKeyLimit); got != 12 {
+		t.Errorf("expected limit from env=12, got %d", got)
+	}
+	if !viper.GetBool("use_wl-clipboard") {
+		t.Error("expected use_wl-clipboard from env=true")
+	}
+}
+
+func TestReadConfig_DefaultsRegistered(t *testing.T) {
+	useLiveReadConfig(t)
+	t.Setenv("HOME", t.TempDir())
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+	if got := viper.GetInt(ViperKeyMaxSize); got != 500 {
+		t.Errorf("expected default max_size=500, got %d", got)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Source tells where an effective setting comes from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Setting is the effective value of a config key.
+type Setting struct {
+	Key    Key
+	Value  any
+	Source Source
+}
+
+// Settings returns the effective value and source of every key.
+// flags is checked for explicitly set overrides (may be nil).
+func Settings(flags *pflag.FlagSet) []Setting {
+	settings := make([]Setting, 0, len(Keys))
+	for _, k := range Keys {
+		settings = append(settings, settingOf(k, flags))
+	}
+	return settings
+}
+
+// Get returns the effective setting of key.
+func Get(key string, flags *pflag.FlagSet) (Setting, bool) {
+	k, ok := LookupKey(key)
+	if !ok {
+		return Setting{}, false
+	}
+	return settingOf(k, flags), true
+}
+
+func settingOf(k Key, flags *pflag.FlagSet) Setting {
+	if k.Flag != "" && flags != nil {
+		if f := flags.Lookup(k.Flag); f != nil && f.Changed {
+			return Setting{Key: k, Value: f.Value.String(), Source: SourceFlag}
+		}
+	}
+
+	value := viper.Get(k.Name)
+	if slices.Contains(profilePathKeys, k.Name) {
+		value = profileSetting(Profile(), k.Name)
+	}
+
+	source := SourceDefault
+	switch {
+	case isSetInEnv(k.Name):
+		source = SourceEnv
+	case viper.InConfig(k.Name) || viper.InConfig(profileKey(Profile(), k.Name)):
+		source = SourceFile
+	}
+	return Setting{Key: k, Value: value, Source: source}
+}
+
+func isSetInEnv(key string) bool {
+	_, ok := LookupEnv(key)
+	return ok
+}
+
+// TargetFile returns the config file to modify: the loaded one, else ~/.homierc.
+func TargetFile() (string, error) {
+	if path := FileUsed(); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, confFileName), nil
+}
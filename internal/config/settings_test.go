@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestSettings_Sources(t *testing.T) {
+	useLiveReadConfig(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(xdgConf, filepath.Join(tmpDir, "xdg"))
+	writeConfigFile(t, filepath.Join(tmpDir, confFileName), "limit: 44\nttl: 2\n")
+	t.Setenv("HOMIE_TTL", "5")
+
+	if err := ReadConfig(); err != nil {
+		t.Fatalf("ReadConfig() failed: %v", err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.BoolP("verbose", "v", false, "verbosity")
+	flags.StringP("order", "o", "selection", "join order")
+	if err := flags.Parse([]string{"-v", "--order", "reverse"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource Source
+	}{
+		{ViperKeyLimit, "44", SourceFile},
+		{ViperKeyTTL, "5", SourceEnv},
+		{ViperKeyMaxSize, "500", SourceDefault},
+		{ViperKeyVerbose, "true", SourceFlag},
+		{ViperKeyJoinOrder, "reverse", SourceFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			s, ok := Get(tt.key, flags)
+			if !ok {
+				t.Fatalf("Get(%q) found no key", tt.key)
+			}
+			if got := fmt.Sprint(s.Value); got != tt.wantValue {
+				t.Errorf("expected value=%q, got %q", tt.wantValue, got)
+			}
+			if s.Source != tt.wantSource {
+				t.Errorf("expected source=%q, got %q", tt.wantSource, s.Source)
+			}
+		})
+	}
+}
+
+func TestGet_UnknownKey(t *testing.T) {
+	if _, ok := Get("colour", nil); ok {
+		t.Fatal("expected unknown key not to be found")
+	}
+}
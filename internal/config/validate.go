@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+// maxSuggestDistance is the largest edit distance for which an unknown key gets a suggestion.
+const maxSuggestDistance = 3
+
+// Problem is a validation finding for a config key.
+type Problem struct {
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// ValidateFile checks the config file at path for unknown keys and invalid values.
+func ValidateFile(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+	var values map[string]any
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	return Validate(values), nil
+}
+
+// Validate checks top-level and profile section values against the registered keys.
+func Validate(values map[string]any) []Problem {
+	problems := validateSection("", values)
+	if raw, ok := values[ViperKeyTransforms]; ok {
+		problems = append(problems, validateTransforms(raw)...)
+	}
+	if raw, ok := values[ViperKeyActions]; ok {
+		problems = append(problems, validateActions(raw)...)
+	}
+
+	if raw, ok := values[ViperKeyProfiles]; ok {
+		profiles, ok := raw.(map[string]any)
+		if !ok {
+			return append(problems, Problem{ViperKeyProfiles, "expected a mapping of profile sections"})
+		}
+		for _, name := range sortedKeys(profiles) {
+			prefix := profileKey(name, "")
+			if !profileNamePattern.MatchString(name) {
+				problems = append(problems, Problem{prefix, "invalid profile name"})
+				continue
+			}
+			section, ok := profiles[name].(map[string]any)
+			if !ok {
+				problems = append(problems, Problem{prefix, "expected a mapping of keys"})
+				continue
+			}
+			problems = append(problems, validateSection(prefix+".", section)...)
+		}
+	}
+	return problems
+}
+
+// validateTransforms checks that transforms maps valid names to commands.
+func validateTransforms(raw any) []Problem {
+	transforms, ok := raw.(map[string]any)
+	if !ok {
+		return []Problem{{ViperKeyTransforms, "expected a mapping of transform names to commands"}}
+	}
+	var problems []Problem
+	for _, name := range sortedKeys(transforms) {
+		key := ViperKeyTransforms + "." + name
+		if !transformNamePattern.MatchString(name) {
+			problems = append(problems, Problem{key, "invalid transform name (use lowercase letters, digits, - and _)"})
+			continue
+		}
+		if cmd, ok := transforms[name].(string); !ok || strings.TrimSpace(cmd) == "" {
+			problems = append(problems, Problem{key, "expected a shell command"})
+		}
+	}
+	return problems
+}
+
+// validateActions checks that actions maps content types to valid action names and command templates.
+func validateActions(raw any) []Problem {
+	types, ok := raw.(map[string]any)
+	if !ok {
+		return []Problem{{ViperKeyActions, "expected a mapping of content types to actions"}}
+	}
+	var problems []Problem
+	for _, kind := range sortedKeys(types) {
+		prefix := ViperKeyActions + "." + kind
+		if !slices.Contains(content.Types, content.Type(kind)) {
+			problems = append(problems, Problem{prefix, "unknown content type"})
+			continue
+		}
+		actions, ok := types[kind].(map[string]any)
+		if !ok {
+			problems = append(problems, Problem{prefix, "expected a mapping of action names to commands"})
+			continue
+		}
+		for _, name := range sortedKeys(actions) {
+			key := prefix + "." + name
+			if !transformNamePattern.MatchString(name) {
+				problems = append(problems, Problem{key, "invalid action name (use lowercase letters, digits, - and _)"})
+				continue
+			}
+			if cmd, ok := actions[name].(string); !ok || strings.TrimSpace(cmd) == "" {
+				problems = append(problems, Problem{key, "expected a command template"})
+			}
+		}
+	}
+	return problems
+}
+
+func validateSection(prefix string, values map[string]any) []Problem {
+	var problems []Problem
+	for _, name := range sortedKeys(values) {
+		if (name == ViperKeyProfiles || name == ViperKeyTransforms || name == ViperKeyActions) && prefix == "" {
+			continue
+		}
+		k, ok := LookupKey(name)
+		if !ok {
+			msg := "unknown key"
+			if s := suggestKey(name); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			problems = append(problems, Problem{prefix + name, msg})
+			continue
+		}
+		if err := CheckValue(k, values[name]); err != nil {
+			problems = append(problems, Problem{prefix + name, err.Error()})
+		}
+	}
+	return problems
+}
+
+// CheckValue reports whether v has the type and range expected by k.
+func CheckValue(k Key, v any) error {
+	switch k.Kind {
+	case KindInt:
+		n, ok := v.(int)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", v)
+		}
+		if n < k.Min {
+			return fmt.Errorf("must be at least %d, got %d", k.Min, n)
+		}
+	case KindBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("expected true or false, got %v", v)
+		}
+	case KindString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", v)
+		}
+		if s != "" && len(k.Choices) > 0 && !slices.Contains(k.Choices, s) {
+			return fmt.Errorf("must be one of %s, got %q", strings.Join(k.Choices, ", "), s)
+		}
+	}
+	return nil
+}
+
+// suggestKey returns the registered key closest to name (empty if none is close enough).
+func suggestKey(name string) string {
+	normalized := envKeyReplacer.Replace(strings.ToLower(name))
+	best, bestDist := "", maxSuggestDistance+1
+	for _, k := range Keys {
+		if envKeyReplacer.Replace(k.Name) == normalized {
+			return k.Name
+		}
+		if d := editDistance(name, k.Name); d < bestDist {
+			best, bestDist = k.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
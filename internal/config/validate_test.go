@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]any
+		wantKeys []string
+	}{
+		{"valid", map[string]any{"limit": 15, "ttl": 7, "clean_up": true, "clipboard_tool": "xsel"}, nil},
+		{"unknown key", map[string]any{"colour": "red"}, []string{"colour"}},
+		{"wrong type", map[string]any{"clean_up": "yes"}, []string{"clean_up"}},
+		{"out of range", map[string]any{"limit": 0, "ttl": -1}, []string{"limit", "ttl"}},
+		{"invalid choice", map[string]any{"clipboard_tool": "pbcopy"}, []string{"clipboard_tool"}},
+		{"profile section", map[string]any{"profiles": map[string]any{
+			"work": map[string]any{"limit": 50, "tll": 3},
+		}}, []string{"profiles.work.tll"}},
+		{"invalid profile name", map[string]any{"profiles": map[string]any{
+			"../x": map[string]any{"limit": 5},
+		}}, []string{"profiles.../x"}},
+		{"profiles not a mapping", map[string]any{"profiles": "work"}, []string{"profiles"}},
+		{"transforms", map[string]any{"transforms": map[string]any{
+			"rot13": "tr 'A-Za-z' 'N-ZA-Mn-za-m'", "Bad Name": "cat", "empty": " ", "number": 5,
+		}}, []string{"transforms.Bad Name", "transforms.empty", "transforms.number"}},
+		{"transforms not a mapping", map[string]any{"transforms": "rot13"}, []string{"transforms"}},
+		{"actions", map[string]any{"actions": map[string]any{
+			"url":   map[string]any{"archive": "curl -s https://web.archive.org/save/{{.Text}}", "Bad": "true"},
+			"links": map[string]any{"open": "xdg-open {{quote .Text}}"},
+			"ip":    "ping",
+		}}, []string{"actions.ip", "actions.links", "actions.url.Bad"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Validate(tt.values)
+			if len(problems) != len(tt.wantKeys) {
+				t.Fatalf("expected %d problems, got %v", len(tt.wantKeys), problems)
+			}
+			for i, key := range tt.wantKeys {
+				if problems[i].Key != key {
+					t.Errorf("problem[%d]: expected key=%q, got %q", i, key, problems[i].Key)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"use_wl_clipboard", "use_wl-clipboard"},
+		{"USE_XCLIP", "use_xclip"},
+		{"tll", "ttl"},
+		{"maxsize", "max_size"},
+		{"completely_unrelated", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestKey(tt.name); got != tt.want {
+				t.Errorf("expected suggestion %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), confFileName)
+	writeConfigFile(t, path, "limit: 15\nuse_wl_clipboard: true\n")
+
+	problems, err := ValidateFile(path)
+	if err != nil {
+		t.Fatalf("ValidateFile() failed: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, `"use_wl-clipboard"`) {
+		t.Fatalf("expected misspelled key with suggestion, got %v", problems)
+	}
+}
+
+func TestValidateFile_InvalidYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), confFileName)
+	writeConfigFile(t, path, "invalid: yaml: content: [unbalanced")
+
+	if _, err := ValidateFile(path); err == nil {
+		t.Fatal("expected error for invalid YAML, got nil")
+	}
+}
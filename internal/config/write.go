@@ -0,0 +1,182 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	confFilePerm = 0o600
+	confIndent   = 2
+)
+
+// ParseValue converts raw into the type of k and checks it.
+func ParseValue(k Key, raw string) (any, error) {
+	var v any
+	switch k.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected an integer, got %q", k.Name, raw)
+		}
+		v = n
+	case KindBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected true or false, got %q", k.Name, raw)
+		}
+		v = b
+	default:
+		v = raw
+	}
+	if err := CheckValue(k, v); err != nil {
+		return nil, fmt.Errorf("%s: %w", k.Name, err)
+	}
+	return v, nil
+}
+
+// SetValue writes key: raw into the config file at path, keeping the rest of the file (and its comments).
+// For a non-default profile the value goes into the profile section.
+// The file is replaced atomically.
+func SetValue(path, key, raw, profile string) error {
+	k, ok := LookupKey(key)
+	if !ok {
+		if s := suggestKey(key); s != "" {
+			return fmt.Errorf("unknown key %q (did you mean %q?)", key, s)
+		}
+		return fmt.Errorf("unknown key %q", key)
+	}
+	value, err := ParseValue(k, raw)
+	if err != nil {
+		return err
+	}
+
+	doc, perm, err := loadDocument(path)
+	if err != nil {
+		return err
+	}
+	section := rootMapping(doc)
+	if profile != "" && profile != DefaultProfile {
+		section = childMapping(childMapping(section, ViperKeyProfiles), profile)
+	}
+
+	var valueNode yaml.Node
+	if err = valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	setMappingValue(section, key, &valueNode)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(confIndent)
+	if err = enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config file %q: %w", path, err)
+	}
+	if err = enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file %q: %w", path, err)
+	}
+	return writeFileAtomic(path, buf.Bytes(), perm)
+}
+
+// loadDocument parses the YAML document at path (an empty one if the file doesn't exist).
+func loadDocument(path string) (*yaml.Node, os.FileMode, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return doc, confFilePerm, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+	if err = yaml.Unmarshal(data, doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		// empty file
+		doc.Kind = yaml.DocumentNode
+	}
+
+	perm := os.FileMode(confFilePerm)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return doc, perm, nil
+}
+
+// rootMapping returns the top-level mapping of doc, creating it if needed.
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return doc.Content[0]
+}
+
+// childMapping returns the mapping stored under key in m, creating (or replacing a non-mapping) if needed.
+func childMapping(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			if m.Content[i+1].Kind != yaml.MappingNode {
+				m.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			return m.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+	return child
+}
+
+// setMappingValue replaces (or appends) the value of key in mapping m, keeping its comments.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			old := m.Content[i+1]
+			value.LineComment, value.HeadComment, value.FootComment = old.LineComment, old.HeadComment, old.FootComment
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it over path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, dbConfDirPerm); err != nil {
+		return fmt.Errorf("failed to create config directory %q: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp config file in %q: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	cleanup := func(err error) error {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		return cleanup(fmt.Errorf("failed to write temp config file %q: %w", tmpPath, err))
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return cleanup(fmt.Errorf("failed to chmod temp config file %q: %w", tmpPath, err))
+	}
+	if err = tmp.Sync(); err != nil {
+		return cleanup(fmt.Errorf("failed to sync temp config file %q: %w", tmpPath, err))
+	}
+	if err = tmp.Close(); err != nil {
+		return cleanup(fmt.Errorf("failed to close temp config file %q: %w", tmpPath, err))
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace config file %q: %w", path, err)
+	}
+	return nil
+}
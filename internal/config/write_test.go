@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readConfigFile returns the contents of path.
+func readConfigFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	return string(data)
+}
+
+func TestSetValue_KeepsCommentsAndOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), confFileName)
+	writeConfigFile(t, path, "limit: 15 # minimum amount\nttl: 7\n")
+
+	if err := SetValue(path, "limit", "30", DefaultProfile); err != nil {
+		t.Fatalf("SetValue() failed: %v", err)
+	}
+
+	got := readConfigFile(t, path)
+	if !strings.Contains(got, "limit: 30 # minimum amount") {
+		t.Errorf("expected updated value with comment, got %q", got)
+	}
+	if !strings.Contains(got, "ttl: 7") {
+		t.Errorf("expected other keys untouched, got %q", got)
+	}
+}
+
+func TestSetValue_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", confFileName)
+
+	if err := SetValue(path, "clean_up", "true", DefaultProfile); err != nil {
+		t.Fatalf("SetValue() failed: %v", err)
+	}
+
+	if got := readConfigFile(t, path); got != "clean_up: true\n" {
+		t.Errorf("expected new file with key, got %q", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != confFilePerm {
+		t.Errorf("expected mode %o, got %o", confFilePerm, perm)
+	}
+}
+
+func TestSetValue_ProfileSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), confFileName)
+	writeConfigFile(t, path, "limit: 15\n")
+
+	if err := SetValue(path, "limit", "40", "work"); err != nil {
+		t.Fatalf("SetValue() failed: %v", err)
+	}
+
+	want := "limit: 15\nprofiles:\n  work:\n    limit: 40\n"
+	if got := readConfigFile(t, path); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSetValue_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"unknown key", "use_wl_clipboard", "true"},
+		{"not an int", "ttl", "week"},
+		{"not a bool", "clean_up", "maybe"},
+		{"below minimum", "limit", "0"},
+		{"invalid choice", "clipboard_tool", "pbcopy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), confFileName)
+			writeConfigFile(t, path, "limit: 15\n")
+
+			if err := SetValue(path, tt.key, tt.value, DefaultProfile); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if got := readConfigFile(t, path); got != "limit: 15\n" {
+				t.Errorf("expected file untouched, got %q", got)
+			}
+		})
+	}
+}
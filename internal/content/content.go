@@ -0,0 +1,185 @@
+package content
+
+import (
+	"encoding/json"
+	"math"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Type is the detected kind of a clipboard entry.
+type Type string
+
+const (
+	TypeEmpty     Type = "empty"
+	TypeText      Type = "text"
+	TypeMultiLine Type = "multi-line"
+	TypeURL       Type = "url"
+	TypeEmail     Type = "email"
+	TypePath      Type = "path"
+	TypeJSON      Type = "json"
+	TypeCode      Type = "code"
+	TypeCommand   Type = "command"
+	TypeNumber    Type = "number"
+	TypeColor     Type = "color"
+	TypeIP        Type = "ip"
+	TypeSecret    Type = "secret"
+)
+
+// ClassifierVersion identifies the rules of Classify. Bump it on every change that can
+// classify a text differently, so that stored content types are recomputed.
+const ClassifierVersion = 2
+
+// Types lists every content type.
+var Types = []Type{
+	TypeText, TypeMultiLine, TypeURL, TypeEmail, TypePath, TypeJSON, TypeCode, TypeCommand,
+	TypeNumber, TypeColor, TypeIP, TypeSecret, TypeEmpty,
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`^[\w.%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}$`)
+	numberPattern = regexp.MustCompile(`^[-+]?(\d+|\d{1,3}(,\d{3})+)(\.\d+)?([eE][-+]?\d+)?$|^0[xX][0-9a-fA-F]+$`)
+	colorPattern  = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$|^(?i:rgba?|hsla?)\([\d\s.,%/]+\)$`)
+	pathPattern   = regexp.MustCompile(`^(~|\.{1,2})?/[^/\n]|^~$|^[A-Za-z]:\\`)
+	// secretPatterns match well-known token formats.
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+		regexp.MustCompile(`^eyJ[\w-]{8,}\.eyJ[\w-]{8,}\.[\w-]+$`),              // JWT
+		regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`),                         // AWS access key id
+		regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_\w{22,})$`), // GitHub
+		regexp.MustCompile(`^xox[abprs]-[\w-]{10,}$`),                           // Slack
+		regexp.MustCompile(`^[sr]k_(live|test)_\w{16,}$`),                       // Stripe
+		regexp.MustCompile(`^AIza[\w-]{35}$`),                                   // Google API key
+		regexp.MustCompile(`^sk-[\w-]{20,}$`),                                   // API keys with an sk- prefix
+	}
+)
+
+const (
+	// minSecretLen and minSecretEntropy (bits per character) flag random-looking tokens as secrets.
+	minSecretLen     = 24
+	maxSecretLen     = 512
+	minSecretEntropy = 4.2
+)
+
+// Classify detects the content type of a clipboard entry.
+// The checks run from the most to the least specific type.
+func Classify(text string) Type {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return TypeEmpty
+	}
+	if isSecret(trimmed) {
+		return TypeSecret
+	}
+
+	singleLine := !strings.Contains(trimmed, "\n")
+	if singleLine {
+		switch {
+		case isURL(trimmed):
+			return TypeURL
+		case emailPattern.MatchString(trimmed):
+			return TypeEmail
+		case isIP(trimmed):
+			return TypeIP
+		case colorPattern.MatchString(trimmed):
+			return TypeColor
+		case numberPattern.MatchString(trimmed):
+			return TypeNumber
+		case pathPattern.MatchString(trimmed) && !strings.ContainsAny(trimmed, "|;&<>$`"):
+			return TypePath
+		}
+	}
+	if isJSON(trimmed) {
+		return TypeJSON
+	}
+	switch DetectLanguage(trimmed) {
+	case LangShell:
+		return TypeCommand
+	case LangNone:
+	default:
+		return TypeCode
+	}
+	if !singleLine {
+		return TypeMultiLine
+	}
+	return TypeText
+}
+
+func isURL(s string) bool {
+	if strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return u.Host != "" && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "ftp")
+}
+
+func isJSON(s string) bool {
+	// plain scalars ("42", "true") are valid JSON but not worth labelling as such
+	if s[0] != '{' && s[0] != '[' {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// isIP accepts addresses, addresses with a port and CIDR prefixes.
+func isIP(s string) bool {
+	if _, err := netip.ParseAddr(s); err == nil {
+		return true
+	}
+	if _, err := netip.ParseAddrPort(s); err == nil {
+		return true
+	}
+	_, err := netip.ParsePrefix(s)
+	return err == nil
+}
+
+// isSecret matches known token formats and long random-looking single words
+// mixing upper case, lower case and digits (hex digests, UUIDs and paths don't qualify).
+func isSecret(s string) bool {
+	for _, p := range secretPatterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	if len(s) < minSecretLen || len(s) > maxSecretLen || strings.ContainsFunc(s, unicode.IsSpace) || isURL(s) {
+		return false
+	}
+	// paths like /tmp/TestRun3859135101/001 mix cases and digits as well
+	if pathPattern.MatchString(s) && strings.Count(s, "/") > 1 {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit && entropy(s) >= minSecretEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
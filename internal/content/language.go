@@ -0,0 +1,132 @@
+package content
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Language is the detected source language of a clipboard entry (LangNone for plain text).
+type Language string
+
+const (
+	LangNone   Language = ""
+	LangGo     Language = "go"
+	LangJSON   Language = "json"
+	LangYAML   Language = "yaml"
+	LangSQL    Language = "sql"
+	LangShell  Language = "shell"
+	LangPython Language = "python"
+	LangDiff   Language = "diff"
+)
+
+// shebangInterpreters maps interpreter names found in a shebang line to languages.
+var shebangInterpreters = map[string]Language{
+	"sh":      LangShell,
+	"bash":    LangShell,
+	"zsh":     LangShell,
+	"dash":    LangShell,
+	"ksh":     LangShell,
+	"python":  LangPython,
+	"python2": LangPython,
+	"python3": LangPython,
+}
+
+var (
+	goPattern       = regexp.MustCompile(`(?m)^\s*(package \w+|import \(|func (\(\w+ \*?\w+\) )?\w+\(|type \w+ (struct|interface) \{)`)
+	sqlPattern      = regexp.MustCompile(`(?is)^\s*(select\s.+\sfrom\s|insert\s+into\s|update\s.+\sset\s|delete\s+from\s|create\s+(table|index|view)\s|alter\s+table\s|drop\s+(table|index|view)\s|with\s+\w+\s+as\s*\()`)
+	yamlLinePattern = regexp.MustCompile(`^\s*(- )?[\w.-]+:(\s|$)|^\s*- \S|^---$`)
+	diffHunkPattern = regexp.MustCompile(`(?m)^@@ -\d+(,\d+)? \+\d+(,\d+)? @@`)
+)
+
+// shellCommands are common first words of shell command lines.
+var shellCommands = map[string]bool{
+	"sudo": true, "cd": true, "ls": true, "echo": true, "export": true, "git": true, "docker": true,
+	"kubectl": true, "make": true, "go": true, "npm": true, "apt": true, "apt-get": true, "dnf": true,
+	"brew": true, "curl": true, "wget": true, "grep": true, "cat": true, "rm": true, "mkdir": true,
+	"cp": true, "mv": true, "chmod": true, "chown": true, "ssh": true, "scp": true, "tar": true,
+	"systemctl": true, "for": true, "if": true, "while": true, "source": true, "pip": true, "$": true,
+}
+
+// DetectLanguage guesses the language of text from its shebang or its syntax.
+func DetectLanguage(text string) Language {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return LangNone
+	}
+	if lang, ok := shebangLanguage(trimmed); ok {
+		return lang
+	}
+
+	switch {
+	case isDiff(trimmed):
+		return LangDiff
+	case isJSON(trimmed):
+		return LangJSON
+	case goPattern.MatchString(trimmed):
+		return LangGo
+	case sqlPattern.MatchString(trimmed):
+		return LangSQL
+	case isYAML(trimmed):
+		return LangYAML
+	case isShell(trimmed):
+		return LangShell
+	default:
+		return LangNone
+	}
+}
+
+// shebangLanguage reads the interpreter from a "#!" first line, e.g. #!/usr/bin/env python3.
+func shebangLanguage(text string) (Language, bool) {
+	if !strings.HasPrefix(text, "#!") {
+		return LangNone, false
+	}
+	firstLine, _, _ := strings.Cut(text[2:], "\n")
+	fields := strings.Fields(firstLine)
+	if len(fields) == 0 {
+		return LangNone, true
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	return shebangInterpreters[interpreter], true
+}
+
+func isDiff(text string) bool {
+	return strings.HasPrefix(text, "diff --git ") ||
+		(strings.HasPrefix(text, "--- ") && strings.Contains(text, "\n+++ ")) ||
+		diffHunkPattern.MatchString(text)
+}
+
+// isYAML requires several lines that mostly look like "key: value" or "- item".
+func isYAML(text string) bool {
+	var total, matched int
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		total++
+		if yamlLinePattern.MatchString(line) {
+			matched++
+		}
+	}
+	return total >= 2 && matched*5 >= total*4
+}
+
+// isShell requires most command lines to start with a well-known command.
+func isShell(text string) bool {
+	var total, matched int
+	for line := range strings.SplitSeq(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		total++
+		if shellCommands[fields[0]] {
+			matched++
+		}
+	}
+	return total > 0 && matched*2 > total
+}
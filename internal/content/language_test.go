@@ -0,0 +1,36 @@
+package content
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Language
+	}{
+		{"empty", "", LangNone},
+		{"prose", "meet me at noon", LangNone},
+		{"bash shebang", "#!/bin/bash\necho hi", LangShell},
+		{"env python shebang", "#!/usr/bin/env python3\nprint(1)", LangPython},
+		{"unknown shebang", "#!/usr/bin/env ruby\nputs 1", LangNone},
+		{"git diff", "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b", LangDiff},
+		{"unified hunk", "@@ -1,2 +1,2 @@\n a\n-b\n+c", LangDiff},
+		{"json", `{"name": "homie", "tags": ["a"]}`, LangJSON},
+		{"go package", "package main\n\nfunc main() {}", LangGo},
+		{"go func", "func (r *Repo) Close() error {\n\treturn nil\n}", LangGo},
+		{"sql select", "SELECT id, name\nFROM users\nWHERE id = 1", LangSQL},
+		{"sql insert lowercase", "insert into t (a) values (1)", LangSQL},
+		{"yaml", "name: homie\nlimit: 20\nprofiles:\n  work:\n    ttl: 7", LangYAML},
+		{"yaml list", "- one\n- two", LangYAML},
+		{"single key is not yaml", "note: buy milk", LangNone},
+		{"shell commands", "cd /tmp\ngit status\nmake build", LangShell},
+		{"sentence starting with if", "if you read this\nplease call me back", LangNone},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectLanguage(tc.text); got != tc.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package cycle keeps the cursor of 'homie cycle', which rotates the clipboard through recent history
+// like an Emacs kill ring. The cursor lives in a small state file, shared with the daemon so that it
+// doesn't move cycled items to the top of the history while a cycle is going on.
+package cycle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+const stateFilePerm = 0o600
+
+// State is the cursor of a cycle: the position of the item in the clipboard (1 -> newest)
+// and when it was put there.
+type State struct {
+	Position  int       `json:"position"`
+	ID        int       `json:"id"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Active reports whether the cycle is still going on at now, i.e. it was moved within timeout.
+func (s State) Active(now time.Time, timeout time.Duration) bool {
+	return s.Position > 0 && now.Sub(s.UpdatedAt) < timeout
+}
+
+// Next returns the position after pos in a history of count items, one older or, with back, one newer.
+// It wraps around at both ends; a cycle that isn't active starts from the newest item (position 1).
+func Next(pos, count int, back bool) int {
+	if count <= 0 {
+		return 0
+	}
+	if pos < 1 || pos > count {
+		pos = 1
+	}
+	if back {
+		pos--
+	} else {
+		pos++
+	}
+	return (pos-1+count)%count + 1
+}
+
+// Load reads the state file at path; a missing file is an inactive cycle.
+func Load(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("failed to read cycle state: %w", err)
+	}
+	var s State
+	if err = json.Unmarshal(data, &s); err != nil {
+		// a damaged file only ends the current cycle
+		return State{}, nil
+	}
+	return s, nil
+}
+
+// Save replaces the state file at path.
+func Save(path string, s State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode cycle state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save cycle state: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to save cycle state: %w", err)
+	}
+	if err = tmp.Chmod(stateFilePerm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to save cycle state: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save cycle state: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save cycle state: %w", err)
+	}
+	return nil
+}
+
+// Reset ends the cycle at path.
+func Reset(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to reset cycle state: %w", err)
+	}
+	return nil
+}
+
+// historyWriter stores clipboard changes, see clipboard.Writer.
+type historyWriter interface {
+	Write(item []byte) error
+}
+
+// Writer passes clipboard changes on to the history, except for the item an active cycle put into the clipboard,
+// so that cycling doesn't reorder the history it cycles through.
+type Writer struct {
+	next    historyWriter
+	path    string
+	timeout atomic.Int64 // time.Duration, set again on config reloads
+	now     func() time.Time
+}
+
+// NewWriter wraps next for the cycle state at path, with the cycle timeout of the current config.
+func NewWriter(next historyWriter, path string, timeout time.Duration) *Writer {
+	w := &Writer{next: next, path: path, now: time.Now}
+	w.SetTimeout(timeout)
+	return w
+}
+
+// SetTimeout replaces the cycle timeout; it is safe to call while the writer is in use.
+func (w *Writer) SetTimeout(timeout time.Duration) {
+	w.timeout.Store(int64(timeout))
+}
+
+// Write stores item unless it is the item of an active cycle.
+func (w *Writer) Write(item []byte) error {
+	// an unreadable state file only means the item is stored as usual
+	if s, err := Load(w.path); err == nil && s.Active(w.now(), time.Duration(w.timeout.Load())) && s.Hash == storage.TextHash(item) {
+		return nil
+	}
+	return w.next.Write(item)
+}
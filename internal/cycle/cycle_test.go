@@ -0,0 +1,125 @@
+package cycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		pos, count int
+		back       bool
+		want       int
+	}{
+		{1, 5, false, 2},
+		{4, 5, false, 5},
+		{5, 5, false, 1},
+		{3, 5, true, 2},
+		{1, 5, true, 5},
+		{0, 5, false, 2},
+		{9, 5, false, 2},
+		{1, 0, false, 0},
+	}
+	for _, tt := range tests {
+		if got := Next(tt.pos, tt.count, tt.back); got != tt.want {
+			t.Errorf("Next(%d, %d, %v) = %d, want %d", tt.pos, tt.count, tt.back, got, tt.want)
+		}
+	}
+}
+
+func TestState_Active(t *testing.T) {
+	now := time.Now()
+	s := State{Position: 2, UpdatedAt: now.Add(-5 * time.Second)}
+	if !s.Active(now, 10*time.Second) {
+		t.Error("expected a recent cycle to be active")
+	}
+	if s.Active(now, 5*time.Second) {
+		t.Error("expected a cycle past its timeout to be inactive")
+	}
+	if (State{UpdatedAt: now}).Active(now, time.Minute) {
+		t.Error("expected the zero position to be inactive")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "homie.db.cycle")
+
+	s, err := Load(path)
+	if err != nil || s != (State{}) {
+		t.Fatalf("expected an inactive state without a file, got %+v, %v", s, err)
+	}
+
+	want := State{Position: 3, ID: 17, Hash: "abc", UpdatedAt: time.Now().Round(0)}
+	if err = Save(path, want); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if s, err = Load(path); err != nil || !s.UpdatedAt.Equal(want.UpdatedAt) || s.Position != 3 || s.ID != 17 || s.Hash != "abc" {
+		t.Errorf("Load() = %+v, %v, want %+v", s, err, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != stateFilePerm {
+		t.Errorf("expected mode %o, got %v (%v)", stateFilePerm, info.Mode().Perm(), err)
+	}
+
+	if err = os.WriteFile(path, []byte("{broken"), stateFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Load(path); err != nil || s != (State{}) {
+		t.Errorf("expected a damaged file to end the cycle, got %+v, %v", s, err)
+	}
+
+	if err = Reset(path); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if err = Reset(path); err != nil {
+		t.Errorf("Reset() without a file failed: %v", err)
+	}
+}
+
+type recordingWriter struct {
+	items []string
+}
+
+func (w *recordingWriter) Write(item []byte) error {
+	w.items = append(w.items, string(item))
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "homie.db.cycle")
+	next := &recordingWriter{}
+	w := NewWriter(next, path, 10*time.Second)
+
+	if err := w.Write([]byte("first")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	s := State{Position: 2, Hash: storage.TextHash([]byte("cycled")), UpdatedAt: time.Now()}
+	if err := Save(path, s); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	_ = w.Write([]byte("cycled"))
+	_ = w.Write([]byte("copied meanwhile"))
+
+	// a reloaded, longer timeout keeps the cycle going
+	w.now = func() time.Time { return s.UpdatedAt.Add(time.Minute) }
+	w.SetTimeout(2 * time.Minute)
+	_ = w.Write([]byte("cycled"))
+
+	// the cycle is over -> stored again
+	w.SetTimeout(10 * time.Second)
+	_ = w.Write([]byte("cycled"))
+
+	want := []string{"first", "copied meanwhile", "cycled"}
+	if len(next.items) != len(want) {
+		t.Fatalf("expected %q to be stored, got %q", want, next.items)
+	}
+	for i := range want {
+		if next.items[i] != want[i] {
+			t.Errorf("expected %q to be stored, got %q", want, next.items)
+			break
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -17,8 +18,15 @@ const (
 	pidFilePerm  = 0o600
 )
 
-// ErrAlreadyRunning is returned when another daemon holds the pidfile lock.
-var ErrAlreadyRunning = errors.New("daemon already running")
+// ReloadSignal asks a running daemon to re-read its config.
+const ReloadSignal = syscall.SIGUSR1
+
+var (
+	// ErrAlreadyRunning is returned when another daemon holds the pidfile lock.
+	ErrAlreadyRunning = errors.New("daemon already running")
+	// ErrNotRunning is returned when signalling a daemon that isn't running.
+	ErrNotRunning = errors.New("daemon not running")
+)
 
 // Lock holds the pidfile open with an exclusive flock for the daemon lifetime.
 type Lock struct {
@@ -69,6 +77,13 @@ func (l *Lock) Release() error {
 	return errors.Join(l.file.Close(), os.Remove(l.path))
 }
 
+// ProfileStatus describes the daemon of a single profile.
+type ProfileStatus struct {
+	Profile string
+	Running bool
+	PID     int
+}
+
 // Status reports whether a daemon holds the pidfile lock.
 func Status() (bool, int, error) {
 	// find path to pidfile
@@ -76,6 +91,31 @@ func Status() (bool, int, error) {
 	if err != nil {
 		return false, 0, err
 	}
+	return statusAt(path)
+}
+
+// StatusAll reports the daemon of every known profile, sorted by profile name.
+func StatusAll() ([]ProfileStatus, error) {
+	files, err := config.ProfilePIDFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]ProfileStatus, 0, len(files))
+	for profile, path := range files {
+		running, pid, err := statusAt(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check daemon of profile %q: %w", profile, err)
+		}
+		statuses = append(statuses, ProfileStatus{Profile: profile, Running: running, PID: pid})
+	}
+	slices.SortFunc(statuses, func(a, b ProfileStatus) int {
+		return strings.Compare(a.Profile, b.Profile)
+	})
+	return statuses, nil
+}
+
+func statusAt(path string) (bool, int, error) {
 	// open file
 	f, err := os.Open(path)
 	if err != nil {
@@ -112,17 +152,33 @@ func Status() (bool, int, error) {
 
 // Stop sends SIGTERM to the daemon PID from the pidfile.
 func Stop() error {
+	err := signalDaemon(syscall.SIGTERM)
+	if errors.Is(err, ErrNotRunning) {
+		return nil
+	}
+	return err
+}
+
+// Reload sends ReloadSignal to the daemon PID from the pidfile.
+func Reload() error {
+	return signalDaemon(ReloadSignal)
+}
+
+func signalDaemon(sig os.Signal) error {
 	// check if running
 	running, pid, err := Status()
-	if err != nil || !running {
+	if err != nil {
 		return err
 	}
-	// find running process & terminate it
+	if !running {
+		return ErrNotRunning
+	}
+	// find running process & signal it
 	proc, err := os.FindProcess(pid)
 	if err != nil {
 		return err
 	}
-	return proc.Signal(syscall.SIGTERM)
+	return proc.Signal(sig)
 }
 
 func readPID(path string) (int, error) {
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func testPIDFile(t *testing.T) string {
@@ -118,3 +120,71 @@ func TestStatus_StalePidfileLivePID(t *testing.T) {
 		t.Fatalf("expected stale pidfile to remain: %v", err)
 	}
 }
+
+func TestStatusAll_ListsRunningProfiles(t *testing.T) {
+	path := testPIDFile(t)
+
+	lock, err := Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	// stale pidfile of another profile next to the default one
+	stale := filepath.Join(filepath.Dir(path), "homie-work.pid")
+	if err := os.WriteFile(stale, []byte("999999\n"), 0600); err != nil {
+		t.Fatalf("failed to write stale pidfile: %v", err)
+	}
+
+	statuses, err := StatusAll()
+	if err != nil {
+		t.Fatalf("StatusAll() failed: %v", err)
+	}
+
+	got := make(map[string]ProfileStatus, len(statuses))
+	for _, s := range statuses {
+		got[s.Profile] = s
+	}
+	if s := got["default"]; !s.Running || s.PID != os.Getpid() {
+		t.Errorf("expected default profile running with pid %d, got %+v", os.Getpid(), s)
+	}
+	if s, ok := got["work"]; !ok || s.Running {
+		t.Errorf("expected work profile listed as not running, got %+v (found=%v)", s, ok)
+	}
+}
+
+func TestReload_NotRunning(t *testing.T) {
+	testPIDFile(t)
+
+	if err := Reload(); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestReload_SignalsDaemon(t *testing.T) {
+	testPIDFile(t)
+
+	// the test process plays the daemon -> catch the signal instead of terminating
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, ReloadSignal)
+	defer signal.Stop(reload)
+
+	lock, err := Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	select {
+	case <-reload:
+	case <-time.After(time.Second):
+		t.Fatal("expected reload signal to be delivered")
+	}
+}
@@ -0,0 +1,353 @@
+// Package diff compares two clipboard texts line by line (unified or side by side)
+// or word by word, for 'homie diff' and the diff action of the history window.
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/highlight"
+)
+
+// Op tells what an Edit does to the first text.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is a line (or word) kept, deleted from the first text or inserted from the second one.
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+const (
+	// DefaultContext is the number of unchanged lines shown around the changes.
+	DefaultContext = 3
+	// DefaultWidth is used by SideBySide when no width is given.
+	DefaultWidth = 130
+
+	// maxCells bounds the LCS table; larger inputs are diffed as a whole replacement.
+	maxCells = 1 << 22
+	// tabWidth expands tabs in the side-by-side columns.
+	tabWidth = 4
+)
+
+// Options control the rendering of a diff.
+type Options struct {
+	// Context is the number of unchanged lines around each change (0 -> changes only).
+	Context int
+	// Theme colors the output (nil for plain text).
+	Theme highlight.Theme
+	// Width is the total width of the side-by-side view (0 -> DefaultWidth).
+	Width int
+	// NameA and NameB label the texts in the header, e.g. "#12".
+	NameA, NameB string
+}
+
+// Lines splits text into lines; a trailing newline doesn't start another line.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// SingleLine reports whether text is at most one line (a trailing newline aside).
+func SingleLine(text string) bool {
+	return !strings.Contains(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Words splits text into runs of spaces and runs of other characters, so joining them restores text.
+func Words(text string) []string {
+	var words []string
+	start, inSpace := 0, false
+	for i, r := range text {
+		if space := unicode.IsSpace(r); i == 0 || space != inSpace {
+			if i > start {
+				words = append(words, text[start:i])
+				start = i
+			}
+			inSpace = space
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// Compute returns the edits turning a into b, based on their longest common subsequence.
+func Compute(a, b []string) []Edit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, s := range a[:pre] {
+		edits = append(edits, Edit{Equal, s})
+	}
+	edits = append(edits, lcs(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, s := range a[len(a)-suf:] {
+		edits = append(edits, Edit{Equal, s})
+	}
+	return edits
+}
+
+// lcs diffs a and b through a table of the common subsequence lengths of their suffixes.
+func lcs(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	var edits []Edit
+	if n*m <= maxCells {
+		table := make([]int32, (n+1)*(m+1))
+		at := func(i, j int) int32 { return table[i*(m+1)+j] }
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					table[i*(m+1)+j] = at(i+1, j+1) + 1
+				} else {
+					table[i*(m+1)+j] = max(at(i+1, j), at(i, j+1))
+				}
+			}
+		}
+		for len(a) > 0 && len(b) > 0 {
+			i, j := n-len(a), m-len(b)
+			switch {
+			case a[0] == b[0]:
+				edits = append(edits, Edit{Equal, a[0]})
+				a, b = a[1:], b[1:]
+			case at(i+1, j) >= at(i, j+1):
+				edits = append(edits, Edit{Delete, a[0]})
+				a = a[1:]
+			default:
+				edits = append(edits, Edit{Insert, b[0]})
+				b = b[1:]
+			}
+		}
+	}
+	for _, s := range a {
+		edits = append(edits, Edit{Delete, s})
+	}
+	for _, s := range b {
+		edits = append(edits, Edit{Insert, s})
+	}
+	return edits
+}
+
+// hunk is a range [start, end) of edits shown together, with the line numbers it starts at.
+type hunk struct {
+	start, end int
+	lineA      int
+	lineB      int
+}
+
+// hunks groups the changes with context unchanged edits around them;
+// changes closer than twice the context share a hunk.
+func hunks(edits []Edit, context int) []hunk {
+	context = max(context, 0)
+	var out []hunk
+	for i, e := range edits {
+		if e.Op == Equal {
+			continue
+		}
+		lo, hi := max(0, i-context), min(len(edits), i+1+context)
+		if n := len(out); n > 0 && lo <= out[n-1].end {
+			out[n-1].end = hi
+			continue
+		}
+		out = append(out, hunk{start: lo, end: hi})
+	}
+
+	lineA, lineB, next := 1, 1, 0
+	for i, e := range edits {
+		if next < len(out) && out[next].start == i {
+			out[next].lineA, out[next].lineB = lineA, lineB
+			next++
+		}
+		if e.Op != Insert {
+			lineA++
+		}
+		if e.Op != Delete {
+			lineB++
+		}
+	}
+	return out
+}
+
+// header returns the range line of h, e.g. "@@ -3,4 +3,5 @@".
+func (h hunk) header(edits []Edit) string {
+	var lenA, lenB int
+	for _, e := range edits[h.start:h.end] {
+		if e.Op != Insert {
+			lenA++
+		}
+		if e.Op != Delete {
+			lenB++
+		}
+	}
+	startA, startB := h.lineA, h.lineB
+	// an empty range names the line before it
+	if lenA == 0 {
+		startA--
+	}
+	if lenB == 0 {
+		startB--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", startA, lenA, startB, lenB)
+}
+
+// Unified returns the line diff of a and b in unified format; empty when they are equal.
+func Unified(a, b string, opts Options) string {
+	edits := Compute(Lines(a), Lines(b))
+	hs := hunks(edits, opts.Context)
+	if len(hs) == 0 {
+		return ""
+	}
+
+	t := opts.Theme
+	var out strings.Builder
+	out.WriteString(t.Paint("--- "+name(opts.NameA, "a"), highlight.Meta) + "\n")
+	out.WriteString(t.Paint("+++ "+name(opts.NameB, "b"), highlight.Meta) + "\n")
+	for _, h := range hs {
+		out.WriteString(t.Paint(h.header(edits), highlight.Meta) + "\n")
+		for _, e := range edits[h.start:h.end] {
+			switch e.Op {
+			case Delete:
+				out.WriteString(t.Paint("-"+e.Text, highlight.Removed))
+			case Insert:
+				out.WriteString(t.Paint("+"+e.Text, highlight.Added))
+			default:
+				out.WriteString(" " + e.Text)
+			}
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// SideBySide returns the line diff of a and b in two columns, like 'diff -y':
+// '|' marks changed lines, '<' deleted and '>' inserted ones. It is empty when they are equal.
+func SideBySide(a, b string, opts Options) string {
+	edits := Compute(Lines(a), Lines(b))
+	hs := hunks(edits, opts.Context)
+	if len(hs) == 0 {
+		return ""
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	col := max((width-3)/2, 1)
+	t := opts.Theme
+	row := func(left, marker, right string, kindL, kindR highlight.Kind) string {
+		left = runewidth.FillRight(cell(left, col), col)
+		if right == "" {
+			return strings.TrimRight(t.Paint(left, kindL)+" "+marker, " ") + "\n"
+		}
+		return t.Paint(left, kindL) + " " + marker + " " + t.Paint(cell(right, col), kindR) + "\n"
+	}
+
+	var out strings.Builder
+	out.WriteString(t.Paint(runewidth.FillRight(cell(name(opts.NameA, "a"), col), col)+"   "+name(opts.NameB, "b"), highlight.Meta) + "\n")
+	for _, h := range hs {
+		out.WriteString(t.Paint(h.header(edits), highlight.Muted) + "\n")
+		run := edits[h.start:h.end]
+		for len(run) > 0 {
+			if run[0].Op == Equal {
+				out.WriteString(row(run[0].Text, " ", run[0].Text, highlight.Plain, highlight.Plain))
+				run = run[1:]
+				continue
+			}
+			// pair the deleted lines of a change with the inserted ones
+			var deleted, inserted []string
+			for len(run) > 0 && run[0].Op == Delete {
+				deleted, run = append(deleted, run[0].Text), run[1:]
+			}
+			for len(run) > 0 && run[0].Op == Insert {
+				inserted, run = append(inserted, run[0].Text), run[1:]
+			}
+			for i := range max(len(deleted), len(inserted)) {
+				switch {
+				case i >= len(inserted):
+					out.WriteString(row(deleted[i], "<", "", highlight.Removed, highlight.Plain))
+				case i >= len(deleted):
+					out.WriteString(row("", ">", inserted[i], highlight.Plain, highlight.Added))
+				default:
+					out.WriteString(row(deleted[i], "|", inserted[i], highlight.Removed, highlight.Added))
+				}
+			}
+		}
+	}
+	return out.String()
+}
+
+// cell fits line into a column of width cells.
+func cell(line string, width int) string {
+	line = strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+	return runewidth.Truncate(line, width, "…")
+}
+
+// WordDiff returns the word diff of two single-line texts, like 'git diff --word-diff':
+// deleted words are shown as [-word-] and inserted ones as {+word+}, or in color with a theme.
+// It is empty when they are equal.
+func WordDiff(a, b string, opts Options) string {
+	edits := Compute(Words(strings.TrimSuffix(a, "\n")), Words(strings.TrimSuffix(b, "\n")))
+	if len(hunks(edits, 0)) == 0 {
+		return ""
+	}
+
+	t := opts.Theme
+	var out strings.Builder
+	for len(edits) > 0 {
+		op := edits[0].Op
+		var run strings.Builder
+		for len(edits) > 0 && edits[0].Op == op {
+			run.WriteString(edits[0].Text)
+			edits = edits[1:]
+		}
+		switch {
+		case op == Equal:
+			out.WriteString(run.String())
+		case t == nil && op == Delete:
+			out.WriteString("[-" + run.String() + "-]")
+		case t == nil:
+			out.WriteString("{+" + run.String() + "+}")
+		case op == Delete:
+			out.WriteString(t.Paint(run.String(), highlight.Removed))
+		default:
+			out.WriteString(t.Paint(run.String(), highlight.Added))
+		}
+	}
+	out.WriteByte('\n')
+	return out.String()
+}
+
+// ItemName labels a history item in the header, e.g. "#12 2026-10-18 09:15:02".
+func ItemName(id int, captured time.Time) string {
+	return Name(fmt.Sprintf("#%d", id), captured)
+}
+
+// Name labels a text in the header by its name and time, e.g. `"ticket 2026-10-18 09:15:02` for a register.
+func Name(name string, t time.Time) string {
+	return name + " " + t.Local().Format(time.DateTime)
+}
+
+func name(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kaliv0/homie/internal/highlight"
+)
+
+func TestCompute(t *testing.T) {
+	got := Compute([]string{"a", "b", "c", "d"}, []string{"a", "c", "x", "d"})
+	want := []Edit{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}, {Insert, "x"}, {Equal, "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute() = %v, want %v", got, want)
+	}
+	if got := Compute(nil, []string{"a"}); !reflect.DeepEqual(got, []Edit{{Insert, "a"}}) {
+		t.Errorf("Compute(nil, [a]) = %v", got)
+	}
+}
+
+func TestCompute_TooLarge(t *testing.T) {
+	a := make([]string, 3000)
+	b := make([]string, 3000)
+	for i := range a {
+		a[i], b[i] = "a"+strings.Repeat("x", i%7), "b"+strings.Repeat("x", i%5)
+	}
+	edits := Compute(a, b)
+	if len(edits) != 6000 || edits[0].Op != Delete || edits[5999].Op != Insert {
+		t.Errorf("expected a whole replacement beyond the table limit, got %d edits", len(edits))
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("kubectl  get pods ")
+	want := []string{"kubectl", "  ", "get", " ", "pods", " "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %q, want %q", got, want)
+	}
+	if Words("") != nil {
+		t.Error("expected no words in an empty text")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	got := Unified(a, b, Options{Context: 1, NameA: "#1", NameB: "#2"})
+	want := `--- #1
++++ #2
+@@ -2,3 +2,3 @@
+ two
+-three
++THREE
+ four
+@@ -9,1 +9,2 @@
+ nine
++ten
+`
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified(a, a, Options{}); got != "" {
+		t.Errorf("expected no diff for equal texts, got %q", got)
+	}
+}
+
+func TestUnified_EmptyRange(t *testing.T) {
+	got := Unified("a\n", "a\nb\n", Options{Context: 0})
+	if !strings.Contains(got, "@@ -1,0 +2,1 @@\n+b\n") {
+		t.Errorf("expected an empty range to name the line before it, got\n%s", got)
+	}
+}
+
+func TestUnified_Colors(t *testing.T) {
+	theme := highlight.Theme{highlight.Removed: "31", highlight.Added: "32"}
+	got := Unified("a", "b", Options{Theme: theme})
+	if !strings.Contains(got, "\x1b[31m-a\x1b[0m") || !strings.Contains(got, "\x1b[32m+b\x1b[0m") {
+		t.Errorf("expected colored changes, got %q", got)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	got := SideBySide("host: a\nport: 1\nkeep\n", "host: b\nkeep\nnew\n", Options{Context: 1, Width: 23})
+	want := "a            b\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		"host: a    | host: b\n" +
+		"port: 1    <\n" +
+		"keep         keep\n" +
+		"           > new\n"
+	if got != want {
+		t.Errorf("SideBySide() =\n%q\nwant\n%q", got, want)
+	}
+	if got := SideBySide("x", "x", Options{}); got != "" {
+		t.Errorf("expected no diff for equal texts, got %q", got)
+	}
+}
+
+func TestSideBySide_Truncates(t *testing.T) {
+	got := SideBySide(strings.Repeat("x", 50), "y\tz", Options{Width: 23})
+	line := strings.Split(got, "\n")[2]
+	if line != "xxxxxxxxx… | y    z" {
+		t.Errorf("expected the columns cut to width, got %q", line)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	got := WordDiff("kubectl get pods -n staging\n", "kubectl get pods -n prod", Options{})
+	if got != "kubectl get pods -n [-staging-]{+prod+}\n" {
+		t.Errorf("WordDiff() = %q", got)
+	}
+	if got := WordDiff("same", "same\n", Options{}); got != "" {
+		t.Errorf("expected no diff for equal texts, got %q", got)
+	}
+}
+
+func TestSingleLine(t *testing.T) {
+	if !SingleLine("token\n") || SingleLine("a\nb") {
+		t.Error("expected a trailing newline to keep a text single-line")
+	}
+}
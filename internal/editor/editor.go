@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	fallbackEditor  = "vi"
+	tempFilePattern = "homie-*.txt"
+)
+
+// Command returns the user's editor command line ($VISUAL, then $EDITOR, then vi).
+func Command() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{fallbackEditor}
+}
+
+// Open runs the editor on path attached to the current terminal and waits for it to exit.
+func Open(path string) error {
+	args := Command()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := errors.AsType[*exec.ExitError](err); ok {
+			return fmt.Errorf("editor %q exited with error: %w", args[0], err)
+		}
+		return fmt.Errorf("failed to run editor %q: %w", args[0], err)
+	}
+	return nil
+}
+
+// EditText opens text in the editor and returns the saved result.
+// The text is staged in a private (0600) temp file that is removed afterwards.
+func EditText(text string) (string, error) {
+	f, err := os.CreateTemp("", tempFilePattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := f.Name()
+	defer func() {
+		_ = os.Remove(path)
+	}()
+
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write temp file %q: %w", path, err)
+	}
+
+	if err = Open(path); err != nil {
+		return "", err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file %q: %w", path, err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,79 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   []string
+	}{
+		{"visual wins", "code -w", "nano", []string{"code", "-w"}},
+		{"editor fallback", "", "nano", []string{"nano"}},
+		{"default", "", "", []string{fallbackEditor}},
+		{"blank visual ignored", "  ", "emacs -nw", []string{"emacs", "-nw"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			if got := Command(); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOpen_RunsEditorOnFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.txt")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "touch")
+
+	if err := Open(path); err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected editor to run on %q: %v", path, err)
+	}
+}
+
+func TestOpen_EditorFailure(t *testing.T) {
+	t.Setenv("VISUAL", "false")
+
+	if err := Open(filepath.Join(t.TempDir(), "x")); err == nil {
+		t.Fatal("expected error when editor exits non-zero, got nil")
+	}
+}
+
+func TestEditText(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	// the "editor" appends a line to the file it is given
+	script := filepath.Join(t.TempDir(), "edit.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho edited >> \"$1\"\n"), 0o700); err != nil {
+		t.Fatalf("failed to write editor script: %v", err)
+	}
+	t.Setenv("EDITOR", script)
+
+	got, err := EditText("original\n")
+	if err != nil {
+		t.Fatalf("EditText() failed: %v", err)
+	}
+	if want := "original\nedited\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestEditText_EditorFailure(t *testing.T) {
+	t.Setenv("VISUAL", "false")
+
+	if _, err := EditText("text"); err == nil {
+		t.Fatal("expected error when editor exits non-zero, got nil")
+	}
+}
@@ -0,0 +1,328 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/editor"
+	"github.com/kaliv0/homie/internal/storage"
+	"github.com/kaliv0/homie/internal/transform"
+)
+
+// Action is an operation offered by the action menu for the selected history items.
+type Action int
+
+const (
+	// ActionSelect copies the selection and closes the window (the default without the menu).
+	ActionSelect Action = iota
+	ActionCopy
+	ActionPaste
+	ActionEdit
+	ActionPin
+	ActionDelete
+	// ActionEditSelect edits the selection in $EDITOR, then copies the result and closes the window.
+	ActionEditSelect
+	// ActionTransform picks transforms from a submenu, then copies the result and closes the window.
+	ActionTransform
+	// ActionLaunch picks a content action (open the URL, run the command, ...) from a submenu and runs it.
+	ActionLaunch
+	// ActionDiff shows the diff of exactly two selected items in the pager.
+	ActionDiff
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionCopy:
+		return "copy (stay)"
+	case ActionPaste:
+		return "paste (stay)"
+	case ActionEdit:
+		return "edit in $EDITOR (save as new entry)"
+	case ActionPin:
+		return "pin / unpin (kept by clean-up)"
+	case ActionDelete:
+		return "delete"
+	case ActionEditSelect:
+		return "edit in $EDITOR, then copy and close"
+	case ActionTransform:
+		return "transform (base64, quote, ...), then copy and close"
+	case ActionLaunch:
+		return "open / run by content (URL, path, command), then close"
+	case ActionDiff:
+		return "diff the two items (pager)"
+	default:
+		return "copy and close"
+	}
+}
+
+const (
+	menuPrompt      = "ACTION >> "
+	transformPrompt = "TRANSFORM >> "
+	headerPreview   = 60
+)
+
+// HistoryEditor modifies clipboard history from the history window; ReadAfter applies the filter of the window.
+type HistoryEditor interface {
+	ReadAfter(cursor storage.Cursor, limit int) ([]storage.ClipboardItem, error)
+	Write(item []byte) error
+	Delete(id int) error
+	TogglePin(id int) (bool, error)
+	ReplaceQueue(texts []string) error
+}
+
+// editText is swapped in tests.
+var editText = editor.EditText
+
+// menuActions lists the actions available for n selected items.
+// Editing works on a single item only, diffing on two; the transform and launch submenus need entries to choose from,
+// and pasting while the window stays open needs a paste into another pane (withPaste).
+// Editing, pinning and deleting change history items, so a selected register (withRegister) rules them out.
+func menuActions(n int, withTransforms, withLaunch, withPaste, withRegister bool) []Action {
+	actions := []Action{
+		ActionSelect, ActionLaunch, ActionEditSelect, ActionTransform,
+		ActionCopy, ActionPaste, ActionDiff, ActionEdit, ActionPin, ActionDelete,
+	}
+	return slices.DeleteFunc(actions, func(a Action) bool {
+		return (a == ActionEdit && n != 1) || (a == ActionDiff && n != 2) ||
+			(a == ActionTransform && !withTransforms) || (a == ActionLaunch && !withLaunch) ||
+			(a == ActionPaste && !withPaste) ||
+			(withRegister && (a == ActionEdit || a == ActionPin || a == ActionDelete))
+	})
+}
+
+// chooseAction shows the action menu for items; ok is false when the menu was dismissed.
+func chooseAction(items []storage.ClipboardItem, withTransforms, withLaunch, withPaste bool) (Action, bool, error) {
+	actions := menuActions(len(items), withTransforms, withLaunch, withPaste, slices.ContainsFunc(items, isRegister))
+	idx, err := fuzzyfinder.Find(
+		actions,
+		func(i int) string {
+			return actions[i].String()
+		},
+		fuzzyfinder.WithHeader(menuHeader(items)),
+		fuzzyfinder.WithPromptString(menuPrompt),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return ActionSelect, false, nil
+	}
+	if err != nil {
+		return ActionSelect, false, err
+	}
+	return actions[idx], true, nil
+}
+
+// chooseTransforms shows the transform submenu; the picked transforms run in the order of selection.
+// ok is false when the menu was dismissed.
+func chooseTransforms(transforms []transform.Transform, items []storage.ClipboardItem) (transform.Pipeline, bool, error) {
+	idxs, err := fuzzyfinder.FindMulti(
+		transforms,
+		func(i int) string {
+			return fmt.Sprintf("%-14s %s", transforms[i].Name, transforms[i].Description)
+		},
+		fuzzyfinder.WithHeader(menuHeader(items)+"  (<tab> to chain several)"),
+		fuzzyfinder.WithPromptString(transformPrompt),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	p := make(transform.Pipeline, 0, len(idxs))
+	for _, i := range idxs {
+		p = append(p, transforms[i])
+	}
+	return p, true, nil
+}
+
+// menuHeader names the selection, e.g. "#12: first line of the item".
+func menuHeader(items []storage.ClipboardItem) string {
+	if len(items) != 1 {
+		return fmt.Sprintf("%d items selected", len(items))
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(items[0].ClipText), "\n")
+	return fmt.Sprintf("%s: %s", itemName(items[0]), runewidth.Truncate(first, headerPreview, "…"))
+}
+
+// session applies menu actions to the database and keeps the loaded history in sync,
+// so the reopened window hot-reloads the changes.
+// Pagination reads after the last loaded item, hence the changes don't disturb it.
+type session struct {
+	history *[]storage.ClipboardItem
+	// total counts the items matching the filter; guarded by mu like history.
+	total int
+	// registers are listed as pseudo-items (see registerItem) before the history items.
+	registers []storage.Register
+	db        HistoryEditor
+	opts      Options
+}
+
+// items returns copies of the history items at idxs.
+func (s *session) items(idxs []int) []storage.ClipboardItem {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]storage.ClipboardItem, 0, len(idxs))
+	for _, i := range idxs {
+		out = append(out, (*s.history)[i])
+	}
+	return out
+}
+
+func (s *session) apply(action Action, items []storage.ClipboardItem) error {
+	switch action {
+	case ActionCopy, ActionPaste:
+		text, err := s.use(items, false, s.opts.Transform)
+		if err != nil {
+			return err
+		}
+		if action == ActionCopy {
+			return s.opts.Copy(text)
+		}
+		return s.opts.Paste(text)
+	case ActionDiff:
+		return s.diff(items)
+	case ActionEdit:
+		return s.edit(items[0])
+	case ActionPin:
+		return s.togglePins(items)
+	case ActionDelete:
+		return s.delete(items)
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+}
+
+// join combines the selected items with the configured joiner.
+func (s *session) join(items []storage.ClipboardItem) (string, error) {
+	if s.opts.Joiner == nil {
+		return joinItems(items), nil
+	}
+	return s.opts.Joiner.Join(items)
+}
+
+// queue replaces the paste queue with items, arranged and formatted by the joiner but kept apart.
+func (s *session) queue(items []storage.ClipboardItem) error {
+	var texts []string
+	if s.opts.Joiner == nil {
+		for _, item := range items {
+			texts = append(texts, item.ClipText)
+		}
+	} else {
+		var err error
+		if texts, err = s.opts.Joiner.Parts(items); err != nil {
+			return err
+		}
+	}
+	for i, text := range texts {
+		var err error
+		if texts[i], err = s.opts.Transform.Apply(text); err != nil {
+			return err
+		}
+	}
+	return s.db.ReplaceQueue(texts)
+}
+
+func (s *session) delete(items []storage.ClipboardItem) error {
+	for _, item := range items {
+		if err := s.db.Delete(item.ID); err != nil {
+			return err
+		}
+		mu.Lock()
+		*s.history = slices.DeleteFunc(*s.history, func(h storage.ClipboardItem) bool { return h.ID == item.ID })
+		s.total--
+		mu.Unlock()
+	}
+	return nil
+}
+
+func (s *session) togglePins(items []storage.ClipboardItem) error {
+	for _, item := range items {
+		pinned, err := s.db.TogglePin(item.ID)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		if i := s.index(item.ID); i >= 0 {
+			(*s.history)[i].Pinned = pinned
+		}
+		mu.Unlock()
+	}
+	return nil
+}
+
+// use returns the text to copy or paste for the selection: joined, run through pipeline and,
+// with edit set, through $EDITOR. With opts.SaveEdits, text changed in the editor is saved as a new entry.
+func (s *session) use(items []storage.ClipboardItem, edit bool, pipeline transform.Pipeline) (string, error) {
+	text, err := s.join(items)
+	if err != nil {
+		return "", err
+	}
+	if text, err = pipeline.Apply(text); err != nil || !edit {
+		return text, err
+	}
+	edited, err := editSelection(text)
+	if err != nil {
+		return "", err
+	}
+	if s.opts.SaveEdits && edited != text && edited != "" {
+		if err = s.db.Write([]byte(edited)); err != nil {
+			return "", err
+		}
+	}
+	return edited, nil
+}
+
+// editSelection opens text in the editor. The newline most editors append on save is dropped
+// unless text ended with one, so a pasted command doesn't run right away.
+func editSelection(text string) (string, error) {
+	edited, err := editText(text)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(text, "\n") {
+		edited = strings.TrimSuffix(edited, "\n")
+	}
+	return edited, nil
+}
+
+// edit saves the edited text as the newest entry; unchanged text is ignored.
+// The entry is listed only if it matches the filter of the window.
+func (s *session) edit(item storage.ClipboardItem) error {
+	edited, err := editSelection(item.ClipText)
+	if err != nil {
+		return err
+	}
+	if edited == item.ClipText {
+		return nil
+	}
+	if err = s.db.Write([]byte(edited)); err != nil {
+		return err
+	}
+	// db reads through the filter, so the newest item is the edited one only if it matches
+	newest, err := s.db.ReadAfter(storage.Cursor{}, 1)
+	if err != nil || len(newest) == 0 || newest[0].ClipText != edited {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// identical text already in history is moved to the top instead of duplicated
+	if i := s.index(newest[0].ID); i >= 0 {
+		*s.history = slices.Delete(*s.history, i, i+1)
+	} else if newest[0].CopyCount <= 1 {
+		// a new entry rather than an unloaded one that was bumped
+		s.total++
+	}
+	*s.history = slices.Insert(*s.history, len(s.registers), newest[0])
+	return nil
+}
+
+// index returns the position of the item with id in the loaded history (-1 if not loaded).
+// The caller holds mu.
+func (s *session) index(id int) int {
+	return slices.IndexFunc(*s.history, func(h storage.ClipboardItem) bool { return h.ID == id })
+}
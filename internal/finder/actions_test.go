@@ -0,0 +1,403 @@
+package finder
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/join"
+	"github.com/kaliv0/homie/internal/storage"
+	"github.com/kaliv0/homie/internal/transform"
+)
+
+// fakeEditor keeps clipboard items in memory, newest first; ids double as timestamps.
+type fakeEditor struct {
+	items  []storage.ClipboardItem
+	nextID int
+	clock  int
+	queue  []string
+	// filter stands for the filter of the window (nil -> every item matches)
+	filter func(storage.ClipboardItem) bool
+}
+
+func (f *fakeEditor) ReadAfter(cursor storage.Cursor, limit int) ([]storage.ClipboardItem, error) {
+	items := f.items
+	if f.filter != nil {
+		items = slices.DeleteFunc(slices.Clone(items), func(it storage.ClipboardItem) bool { return !f.filter(it) })
+	}
+	start := 0
+	if !cursor.IsZero() {
+		start = len(items)
+		for i, it := range items {
+			if it.TimeStamp.Before(cursor.TimeStamp) {
+				start = i
+				break
+			}
+		}
+	}
+	return slices.Clone(items[start:min(start+limit, len(items))]), nil
+}
+
+func (f *fakeEditor) now() time.Time {
+	f.clock++
+	return time.Unix(int64(f.clock), 0)
+}
+
+func (f *fakeEditor) Write(item []byte) error {
+	if i := slices.IndexFunc(f.items, func(it storage.ClipboardItem) bool { return it.ClipText == string(item) }); i >= 0 {
+		existing := f.items[i]
+		existing.TimeStamp = f.now()
+		f.items = slices.Insert(slices.Delete(f.items, i, i+1), 0, existing)
+		return nil
+	}
+	f.nextID++
+	f.items = slices.Insert(f.items, 0, storage.ClipboardItem{ID: f.nextID, ClipText: string(item), TimeStamp: f.now()})
+	return nil
+}
+
+func (f *fakeEditor) Delete(id int) error {
+	f.items = slices.DeleteFunc(f.items, func(it storage.ClipboardItem) bool { return it.ID == id })
+	return nil
+}
+
+func (f *fakeEditor) TogglePin(id int) (bool, error) {
+	i := slices.IndexFunc(f.items, func(it storage.ClipboardItem) bool { return it.ID == id })
+	f.items[i].Pinned = !f.items[i].Pinned
+	return f.items[i].Pinned, nil
+}
+
+func (f *fakeEditor) ReplaceQueue(texts []string) error {
+	f.queue = slices.Clone(texts)
+	return nil
+}
+
+func (f *fakeEditor) Count() (int, error) {
+	return len(f.items), nil
+}
+
+func (f *fakeEditor) Close() error {
+	return nil
+}
+
+// newSession loads the first `loaded` of n items (ids n..1, newest first) into the history.
+func newSession(t *testing.T, n, loaded int) (*session, *fakeEditor, *[]storage.ClipboardItem) {
+	t.Helper()
+	db := &fakeEditor{nextID: n, clock: n}
+	for id := n; id >= 1; id-- {
+		db.items = append(db.items, storage.ClipboardItem{
+			ID: id, ClipText: "item-" + string(rune('a'+id-1)), TimeStamp: time.Unix(int64(id), 0),
+		})
+	}
+	history, _ := db.ReadAfter(storage.Cursor{}, loaded)
+	s := &session{history: &history, total: n, db: db}
+	return s, db, &history
+}
+
+// nextPage reads the page after the last loaded item, like the pagination goroutine.
+func nextPage(t *testing.T, db *fakeEditor, history []storage.ClipboardItem) []storage.ClipboardItem {
+	t.Helper()
+	page, err := db.ReadAfter(storage.CursorAfter(history[len(history)-1]), 5)
+	if err != nil {
+		t.Fatalf("ReadAfter() failed: %v", err)
+	}
+	return page
+}
+
+// useEditor replaces the $EDITOR round-trip for the test.
+func useEditor(t *testing.T, edit func(string) (string, error)) {
+	t.Helper()
+	orig := editText
+	editText = edit
+	t.Cleanup(func() { editText = orig })
+}
+
+func ids(items []storage.ClipboardItem) []int {
+	out := make([]int, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.ID)
+	}
+	return out
+}
+
+func TestSession_Delete(t *testing.T) {
+	s, db, history := newSession(t, 5, 3)
+
+	if err := s.apply(ActionDelete, s.items([]int{0, 2})); err != nil {
+		t.Fatalf("apply(delete) failed: %v", err)
+	}
+	if got, want := ids(*history), []int{4}; !slices.Equal(got, want) {
+		t.Errorf("expected loaded ids %v, got %v", want, got)
+	}
+	if got, want := ids(db.items), []int{4, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("expected stored ids %v, got %v", want, got)
+	}
+	if s.total != 3 {
+		t.Errorf("expected total 3 after deleting 2 of 5, got %d", s.total)
+	}
+
+	// next page continues right after the loaded items
+	if got, want := ids(nextPage(t, db, *history)), []int{2, 1}; !slices.Equal(got, want) {
+		t.Errorf("expected next page %v, got %v", want, got)
+	}
+}
+
+func TestSession_TogglePin(t *testing.T) {
+	s, db, history := newSession(t, 3, 3)
+
+	if err := s.apply(ActionPin, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(pin) failed: %v", err)
+	}
+	if !(*history)[1].Pinned || !db.items[1].Pinned {
+		t.Errorf("expected item to be pinned in history and storage")
+	}
+
+	if err := s.apply(ActionPin, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(unpin) failed: %v", err)
+	}
+	if (*history)[1].Pinned {
+		t.Errorf("expected item to be unpinned")
+	}
+}
+
+func TestSession_EditSavesNewEntry(t *testing.T) {
+	s, db, history := newSession(t, 4, 2)
+	useEditor(t, func(text string) (string, error) { return strings.ToUpper(text), nil })
+
+	if err := s.apply(ActionEdit, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(edit) failed: %v", err)
+	}
+	if got := (*history)[0].ClipText; got != "ITEM-C" {
+		t.Errorf("expected edited entry on top, got %q", got)
+	}
+	if got, want := ids(*history), []int{5, 4, 3}; !slices.Equal(got, want) {
+		t.Errorf("expected loaded ids %v, got %v", want, got)
+	}
+	if len(db.items) != 5 || s.total != 5 {
+		t.Errorf("expected original to be kept next to the edited copy, got %d items (total %d)", len(db.items), s.total)
+	}
+
+	if got, want := ids(nextPage(t, db, *history)), []int{2, 1}; !slices.Equal(got, want) {
+		t.Errorf("expected next page %v, got %v", want, got)
+	}
+}
+
+func TestSession_EditOutsideFilter(t *testing.T) {
+	s, db, history := newSession(t, 3, 3)
+	db.filter = func(it storage.ClipboardItem) bool { return strings.HasPrefix(it.ClipText, "item-") }
+	useEditor(t, func(text string) (string, error) { return "other", nil })
+
+	if err := s.apply(ActionEdit, s.items([]int{0})); err != nil {
+		t.Fatalf("apply(edit) failed: %v", err)
+	}
+	if got, want := ids(*history), []int{3, 2, 1}; !slices.Equal(got, want) || s.total != 3 {
+		t.Errorf("expected the edited entry outside the filter not to be listed, got %v (total %d)", got, s.total)
+	}
+	if len(db.items) != 4 {
+		t.Errorf("expected the edited entry to be stored, got %d items", len(db.items))
+	}
+}
+
+func TestSession_EditBelowRegisters(t *testing.T) {
+	s, _, history := newSession(t, 3, 3)
+	s.registers = []storage.Register{{Name: "a", Text: "reg-a"}}
+	prependRegisters(history, s.registers)
+	useEditor(t, func(text string) (string, error) { return strings.ToUpper(text), nil })
+
+	if err := s.apply(ActionEdit, s.items([]int{2})); err != nil {
+		t.Fatalf("apply(edit) failed: %v", err)
+	}
+	if got, want := ids(*history), []int{0, 4, 3, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("expected the edited entry right below the registers, got ids %v", got)
+	}
+}
+
+func TestSession_EditUnchanged(t *testing.T) {
+	s, db, history := newSession(t, 2, 2)
+	useEditor(t, func(text string) (string, error) { return text, nil })
+
+	if err := s.apply(ActionEdit, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(edit) failed: %v", err)
+	}
+	if len(*history) != 2 || len(db.items) != 2 {
+		t.Errorf("expected no new entry for unchanged text, got %d loaded / %d stored", len(*history), len(db.items))
+	}
+}
+
+func TestSession_UseEdited(t *testing.T) {
+	tests := []struct {
+		name      string
+		save      bool
+		edit      func(string) (string, error)
+		want      string
+		wantSaved int
+	}{
+		{"editor newline dropped", false, func(text string) (string, error) { return text + " --force\n", nil },
+			"item-b item-a --force", 2},
+		{"saved as new entry", true, func(text string) (string, error) { return strings.ToUpper(text), nil },
+			"ITEM-B ITEM-A", 3},
+		{"unchanged not saved", true, func(text string) (string, error) { return text + "\n", nil },
+			"item-b item-a", 2},
+		{"emptied not saved", true, func(string) (string, error) { return "", nil }, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db, _ := newSession(t, 2, 2)
+			s.opts.SaveEdits = tt.save
+			useEditor(t, tt.edit)
+
+			got, err := s.use(s.items([]int{0, 1}), true, nil)
+			if err != nil {
+				t.Fatalf("use() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if len(db.items) != tt.wantSaved {
+				t.Errorf("expected %d stored items, got %d", tt.wantSaved, len(db.items))
+			}
+		})
+	}
+}
+
+func TestSession_UseWithoutEdit(t *testing.T) {
+	s, _, _ := newSession(t, 2, 2)
+	useEditor(t, func(string) (string, error) {
+		t.Fatal("editor opened without edit")
+		return "", nil
+	})
+
+	if got, err := s.use(s.items([]int{1}), false, nil); err != nil || got != "item-a" {
+		t.Errorf("expected %q, got %q (err=%v)", "item-a", got, err)
+	}
+}
+
+func TestSession_UseTransformed(t *testing.T) {
+	catalog, err := transform.NewCatalog(nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() failed: %v", err)
+	}
+	pipeline, err := catalog.Pipeline([]string{"upper", "shell-quote"})
+	if err != nil {
+		t.Fatalf("Pipeline() failed: %v", err)
+	}
+
+	s, _, _ := newSession(t, 2, 2)
+	// the editor sees the transformed text
+	useEditor(t, func(text string) (string, error) { return text + " x", nil })
+	got, err := s.use(s.items([]int{0, 1}), true, pipeline)
+	if err != nil {
+		t.Fatalf("use() failed: %v", err)
+	}
+	if want := "'ITEM-B ITEM-A' x"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// copy (stay) applies the --transform pipeline
+	var copied string
+	s.opts.Transform = pipeline
+	s.opts.Copy = func(text string) error { copied = text; return nil }
+	if err := s.apply(ActionCopy, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(copy) failed: %v", err)
+	}
+	if copied != "ITEM-A" {
+		t.Errorf("expected transformed copy, got %q", copied)
+	}
+}
+
+func TestSession_Queue(t *testing.T) {
+	catalog, err := transform.NewCatalog(nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() failed: %v", err)
+	}
+	pipeline, err := catalog.Pipeline([]string{"upper"})
+	if err != nil {
+		t.Fatalf("Pipeline() failed: %v", err)
+	}
+	joiner, err := join.New(join.Options{Order: join.OrderChronological})
+	if err != nil {
+		t.Fatalf("join.New() failed: %v", err)
+	}
+
+	s, db, _ := newSession(t, 3, 3)
+	s.opts.Joiner = joiner
+	s.opts.Transform = pipeline
+	// selected newest first -> queued oldest first, each item transformed on its own
+	if err = s.queue(s.items([]int{0, 2})); err != nil {
+		t.Fatalf("queue() failed: %v", err)
+	}
+	if want := []string{"ITEM-A", "ITEM-C"}; !slices.Equal(db.queue, want) {
+		t.Errorf("expected queue %q, got %q", want, db.queue)
+	}
+}
+
+func TestEditSelection_KeepsTrailingNewline(t *testing.T) {
+	useEditor(t, func(text string) (string, error) { return text, nil })
+
+	if got, _ := editSelection("line\n"); got != "line\n" {
+		t.Errorf("expected the original newline to be kept, got %q", got)
+	}
+}
+
+func TestSession_CopyAndPaste(t *testing.T) {
+	s, _, _ := newSession(t, 2, 2)
+	var copied, pasted string
+	s.opts.Copy = func(text string) error { copied = text; return nil }
+	s.opts.Paste = func(text string) error { pasted = text; return nil }
+
+	if err := s.apply(ActionCopy, s.items([]int{0, 1})); err != nil {
+		t.Fatalf("apply(copy) failed: %v", err)
+	}
+	if err := s.apply(ActionPaste, s.items([]int{1})); err != nil {
+		t.Fatalf("apply(paste) failed: %v", err)
+	}
+	if copied != "item-b item-a" || pasted != "item-a" {
+		t.Errorf("unexpected copy %q / paste %q", copied, pasted)
+	}
+}
+
+func TestMenuActions(t *testing.T) {
+	if !slices.Contains(menuActions(1, false, false, true, false), ActionEdit) {
+		t.Error("expected edit for a single item")
+	}
+	if slices.Contains(menuActions(2, false, false, true, false), ActionEdit) {
+		t.Error("expected no edit for multiple items")
+	}
+	if slices.Contains(menuActions(1, false, false, true, false), ActionTransform) || !slices.Contains(menuActions(1, true, false, true, false), ActionTransform) {
+		t.Error("expected the transform submenu only when transforms are available")
+	}
+	if slices.Contains(menuActions(1, false, false, true, false), ActionLaunch) || !slices.Contains(menuActions(1, false, true, true, false), ActionLaunch) {
+		t.Error("expected the launch submenu only when content actions are available")
+	}
+	if slices.Contains(menuActions(1, false, false, true, false), ActionDiff) || !slices.Contains(menuActions(2, false, false, true, false), ActionDiff) ||
+		slices.Contains(menuActions(3, false, false, true, false), ActionDiff) {
+		t.Error("expected diff for exactly two items")
+	}
+	if slices.Contains(menuActions(1, false, false, false, false), ActionPaste) {
+		t.Error("expected no paste action without a pane to paste into")
+	}
+	for _, a := range menuActions(1, false, false, true, true) {
+		if a == ActionEdit || a == ActionPin || a == ActionDelete {
+			t.Errorf("expected no %q with a register selected", a)
+		}
+	}
+	if menuActions(2, true, true, true, false)[0] != ActionSelect {
+		t.Error("expected 'copy and close' to be the default action")
+	}
+}
+
+func TestMenuHeader(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	if got := menuHeader([]storage.ClipboardItem{{ID: 7, ClipText: "  first\nsecond"}}); got != "#7: first" {
+		t.Errorf("unexpected header %q", got)
+	}
+	if got := menuHeader([]storage.ClipboardItem{{ID: 7, ClipText: long}}); len([]rune(got)) != len("#7: ")+headerPreview {
+		t.Errorf("expected header truncated to %d columns, got %q", headerPreview, got)
+	}
+	if got := menuHeader([]storage.ClipboardItem{registerItem(storage.Register{Name: "ticket", Text: "HOM-42"})}); got != `"ticket: HOM-42` {
+		t.Errorf("unexpected register header %q", got)
+	}
+	if got := menuHeader(make([]storage.ClipboardItem, 3)); got != "3 items selected" {
+		t.Errorf("unexpected header %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kaliv0/homie/internal/diff"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// fallbackPager is used without $PAGER; -R keeps the colors.
+var fallbackPager = []string{"less", "-R"}
+
+// pageText is swapped in tests.
+var pageText = page
+
+// diff shows the diff of two selected items in the pager: word by word for single-line items,
+// else unified, in the order of selection.
+func (s *session) diff(items []storage.ClipboardItem) error {
+	a, b := items[0], items[1]
+	opts := diff.Options{
+		Context: diff.DefaultContext,
+		Theme:   s.opts.Theme,
+		NameA:   diff.Name(itemName(a), a.TimeStamp),
+		NameB:   diff.Name(itemName(b), b.TimeStamp),
+	}
+	var text string
+	if diff.SingleLine(a.ClipText) && diff.SingleLine(b.ClipText) {
+		text = diff.WordDiff(a.ClipText, b.ClipText, opts)
+	} else {
+		text = diff.Unified(a.ClipText, b.ClipText, opts)
+	}
+	if text == "" {
+		text = fmt.Sprintf("%s and %s are identical\n", itemName(a), itemName(b))
+	}
+	return pageText(text)
+}
+
+// page shows text in $PAGER (less -R by default) and waits for it to exit.
+func page(text string) error {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = fallbackPager
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	// stdout carries the selection to the shell integration -> page on stderr
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=R")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run pager %q: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func stubPager(t *testing.T) *string {
+	t.Helper()
+	var paged string
+	orig := pageText
+	pageText = func(text string) error {
+		paged = text
+		return nil
+	}
+	t.Cleanup(func() { pageText = orig })
+	return &paged
+}
+
+func TestSession_Diff(t *testing.T) {
+	paged := stubPager(t)
+	s := &session{}
+
+	a := storage.ClipboardItem{ID: 1, ClipText: "host: a\nport: 1\n"}
+	b := storage.ClipboardItem{ID: 2, ClipText: "host: b\nport: 1\n"}
+	if err := s.diff([]storage.ClipboardItem{a, b}); err != nil {
+		t.Fatalf("diff() failed: %v", err)
+	}
+	if !strings.HasPrefix(*paged, "--- #1 ") || !strings.Contains(*paged, "-host: a\n+host: b\n port: 1\n") {
+		t.Errorf("expected a unified diff in selection order, got\n%s", *paged)
+	}
+
+	a.ClipText, b.ClipText = "token abc", "token abd"
+	if err := s.diff([]storage.ClipboardItem{a, b}); err != nil {
+		t.Fatalf("diff() failed: %v", err)
+	}
+	if *paged != "token [-abc-]{+abd+}\n" {
+		t.Errorf("expected a word diff of single-line items, got %q", *paged)
+	}
+
+	if err := s.diff([]storage.ClipboardItem{a, a}); err != nil {
+		t.Fatalf("diff() failed: %v", err)
+	}
+	if *paged != "#1 and #1 are identical\n" {
+		t.Errorf("unexpected output for equal items: %q", *paged)
+	}
+}
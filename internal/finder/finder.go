@@ -3,30 +3,76 @@ package finder
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ktr0731/go-fuzzyfinder"
 
+	"github.com/kaliv0/homie/internal/action"
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/join"
 	"github.com/kaliv0/homie/internal/log"
 	"github.com/kaliv0/homie/internal/storage"
+	"github.com/kaliv0/homie/internal/transform"
 )
 
 // HistoryReader provides paginated access to clipboard history.
 type HistoryReader interface {
-	Read(offset, limit int) ([]storage.ClipboardItem, error)
+	ReadAfter(cursor storage.Cursor, limit int) ([]storage.ClipboardItem, error)
 	Count() (int, error)
 	Close() error
 }
 
-const prompt = "D'OH >> "
+const (
+	prompt    = "D'OH >> "
+	pinMarker = "★ "
+	// prefetchMargin is how close the cursor gets to the last loaded item before the next page is loaded.
+	prefetchMargin = 10
+)
 
 var mu sync.RWMutex
 
+// Options configures the history window.
+type Options struct {
+	Limit int
+	// Filter restricts the listed items; Query pre-fills the fuzzy search.
+	Filter storage.Filter
+	Query  string
+	// Theme colors the preview (nil for plain text).
+	Theme highlight.Theme
+	// Actions opens the action menu on the selected items instead of returning them right away.
+	Actions bool
+	// Copy and Paste back the actions that keep the history window open;
+	// a nil Paste (no pane to paste into) hides the paste action.
+	Copy  func(text string) error
+	Paste func(text string) error
+	// Joiner combines multiple selected items (nil -> separated by spaces in selection order).
+	Joiner *join.Joiner
+	// Edit opens the selection in $EDITOR before it is returned;
+	// SaveEdits stores changed text as a new history entry.
+	Edit      bool
+	SaveEdits bool
+	// Transform rewrites the selection before it is returned, copied or pasted;
+	// Transforms are offered by the transform submenu instead.
+	Transform  transform.Pipeline
+	Transforms []transform.Transform
+	// Launcher offers content actions (open a URL, run a command, ...) for a single selected item.
+	Launcher *action.Catalog
+	// Queue replaces the paste queue with the selected items instead of returning them
+	// (in the order of Joiner, each one formatted and transformed on its own).
+	Queue bool
+	// Registers lists the named registers as a section before the history items.
+	Registers bool
+}
+
 // ListHistory loads clipboard history and presents a fuzzy finder.
-func ListHistory(dbPath string, limit int) (string, error) {
+// It returns the selected items joined by opts.Joiner (empty when nothing was selected).
+func ListHistory(dbPath string, opts Options) (string, error) {
 	// load history
-	db, err := storage.NewRepository(dbPath)
+	db, err := storage.Open(dbPath)
 	if err != nil {
 		return "", err
 	}
@@ -38,12 +84,12 @@ func ListHistory(dbPath string, limit int) (string, error) {
 	}()
 
 	// display & search
-	offset := 0
-	history, err := db.Read(offset, limit)
+	src := filteredReader{db, opts.Filter}
+	history, err := src.ReadAfter(storage.Cursor{}, opts.Limit)
 	if err != nil {
 		return "", err
 	}
-	total, err := db.Count()
+	total, err := src.Count()
 	if err != nil {
 		return "", err
 	}
@@ -54,53 +100,136 @@ func ListHistory(dbPath string, limit int) (string, error) {
 	// Wait for the pagination goroutine to finish before db.Close() runs,
 	// so an in-flight db.Read() isn't interrupted by a closed connection.
 	var wg sync.WaitGroup
-	loadMore := handleLoadChannel(ctx, &history, db, offset, limit, total, &wg)
+	loadMore := handleLoadChannel(ctx, &history, src, opts.Limit, &wg)
 	defer func() {
 		close(loadMore)
 		wg.Wait()
 	}()
 
-	idxs, err := findItemIdxs(&history, loadMore)
-	if err != nil {
-		return "", err
+	s := &session{history: &history, total: total, db: src, opts: opts}
+	// the registers go in after handleLoadChannel took its cursor from the last history item, so pagination skips them
+	if opts.Registers {
+		if s.registers, err = db.Registers(); err != nil {
+			return "", err
+		}
+		prependRegisters(&history, s.registers)
 	}
+	for {
+		idxs, err := findItemIdxs(s, loadMore)
+		if err != nil {
+			return "", err
+		}
+		// return selected item (from preview window)
+		if len(idxs) == 0 {
+			return "", nil
+		}
+		selected := s.items(idxs)
+		if opts.Queue {
+			return "", s.queue(selected)
+		}
+		if !opts.Actions {
+			return s.use(selected, opts.Edit, opts.Transform)
+		}
 
-	// return selected item (from preview window)
-	if len(idxs) == 0 {
-		return "", nil
+		action, ok, err := chooseAction(selected, len(opts.Transforms) > 0, len(s.launchActions(selected)) > 0,
+			opts.Paste != nil)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			// menu dismissed -> back to the history window
+			continue
+		}
+		switch action {
+		case ActionSelect:
+			return s.use(selected, opts.Edit, opts.Transform)
+		case ActionEditSelect:
+			return s.use(selected, true, opts.Transform)
+		case ActionTransform:
+			pipeline, ok, err := chooseTransforms(opts.Transforms, selected)
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return s.use(selected, opts.Edit, pipeline)
+			}
+			// submenu dismissed -> back to the history window
+			continue
+		case ActionLaunch:
+			ok, err := s.launch(selected[0])
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return "", nil
+			}
+			continue
+		}
+		// the other actions keep the window open -> reopen it on the updated history
+		if err = s.apply(action, selected); err != nil {
+			return "", err
+		}
 	}
+}
+
+// filteredReader pages through the history items matching filter.
+type filteredReader struct {
+	*storage.Repository
+	filter storage.Filter
+}
+
+func (r filteredReader) ReadAfter(cursor storage.Cursor, limit int) ([]storage.ClipboardItem, error) {
+	return r.SearchAfter(r.filter, cursor, limit)
+}
+
+func (r filteredReader) Count() (int, error) {
+	return r.CountMatching(r.filter)
+}
 
-	out := make([]string, 0, len(idxs))
-	for _, i := range idxs {
-		out = append(out, history[i].ClipText)
+func joinItems(items []storage.ClipboardItem) string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.ClipText)
 	}
-	return strings.Join(out, " "), nil
+	return strings.Join(out, " ")
 }
 
+// handleLoadChannel appends the next page of history after each signal.
+// *history must hold the first page when it is called; the following pages are read
+// after the cursor of the last loaded item, so items written or bumped by the daemon
+// meanwhile are neither skipped nor repeated.
 func handleLoadChannel(ctx context.Context, history *[]storage.ClipboardItem, db HistoryReader,
-	offset, limit, total int, wg *sync.WaitGroup) chan struct{} {
+	limit int, wg *sync.WaitGroup) chan struct{} {
+	mu.RLock()
+	var cursor storage.Cursor
+	if n := len(*history); n > 0 {
+		cursor = storage.CursorAfter((*history)[n-1])
+	}
+	// a short first page means there is nothing more to load
+	exhausted := len(*history) < limit
+	mu.RUnlock()
+
 	// signal more items needed -> triggered from fuzzyfinder.WithPreviewWindow
 	loadMore := make(chan struct{}, 1)
 	wg.Go(func() {
-		loadedOffset := offset
 		for {
 			select {
 			case _, ok := <-loadMore:
 				if !ok {
 					return
 				}
-				candidateOffset := loadedOffset + limit
-				if candidateOffset >= total {
+				if exhausted {
 					continue
 				}
-				loadedOffset = candidateOffset
-				page, err := db.Read(loadedOffset, limit)
+				page, err := db.ReadAfter(cursor, limit)
 				if err != nil {
-					log.Logger().Printf("failed to load more history items (offset=%d, limit=%d, total=%d): %v\n",
-						loadedOffset, limit, total, err)
+					log.Logger().Printf("failed to load more history items (after id=%d, limit=%d): %v\n",
+						cursor.ID, limit, err)
 					continue
 				}
+				exhausted = len(page) < limit
 				if len(page) > 0 {
+					cursor = storage.CursorAfter(page[len(page)-1])
 					mu.Lock()
 					*history = append(*history, page...)
 					mu.Unlock()
@@ -113,29 +242,84 @@ func handleLoadChannel(ctx context.Context, history *[]storage.ClipboardItem, db
 	return loadMore
 }
 
-func findItemIdxs(history *[]storage.ClipboardItem, loadMore chan struct{}) ([]int, error) {
+// shouldPrefetch reports whether the next page is needed for the item at index i (-1 -> no match)
+// out of loaded items.
+func shouldPrefetch(i, loaded int) bool {
+	return i < 0 || i >= loaded-prefetchMargin
+}
+
+// loadStatus reports how much of the history is loaded.
+func loadStatus(loaded, total int) string {
+	return fmt.Sprintf("loaded %d of %d", loaded, max(total, loaded))
+}
+
+// listLabel is the list entry of an item: pin marker, a type badge (none for plain text), #tags and the note
+// before the text, so that fuzzy search finds items by their labels too.
+func listLabel(item storage.ClipboardItem) string {
+	var b strings.Builder
+	if item.Pinned {
+		b.WriteString(pinMarker)
+	}
+	if t := item.Type(); t != content.TypeText {
+		b.WriteString("[" + string(t) + "] ")
+	}
+	for _, tag := range item.Tags {
+		b.WriteString("#" + tag + " ")
+	}
+	if item.Note != "" {
+		b.WriteString("«" + item.Note + "» ")
+	}
+	b.WriteString(item.ClipText)
+	return b.String()
+}
+
+func findItemIdxs(s *session, loadMore chan struct{}) ([]int, error) {
+	history := s.history
+	regs := s.registers
 	idxs, err := fuzzyfinder.FindMulti(
 		history,
-		// itemFunc -> returns items in main history list
+		// itemFunc -> returns items in main history list, the registers section first
 		func(i int) string {
-			return (*history)[i].ClipText
+			if i < len(regs) {
+				return registerLabel(regs[i])
+			}
+			return listLabel((*history)[i])
 		},
 		// opts for fuzzy-finder window
 		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
-			if i == -1 {
-				// no item found while searching
+			mu.RLock()
+			loaded, total := len(*history), s.total
+			var item storage.ClipboardItem
+			if i >= 0 && i < loaded {
+				item = (*history)[i]
+			}
+			mu.RUnlock()
+
+			// the cursor is close to the oldest loaded item or the search found nothing -> load the next page
+			if shouldPrefetch(i, loaded) {
 				select {
 				case loadMore <- struct{}{}:
 				default:
 				}
-				return ""
+			}
+			status := loadStatus(loaded-len(regs), total)
+			if i == -1 {
+				return status
 			}
 			// return string to display in previewWindow
-			return (*history)[i].ClipText
+			if i < len(regs) {
+				return renderRegisterPreview(regs[i], status, width, height, time.Now(), s.opts.Theme)
+			}
+			return renderPreview(item, status, width, height, time.Now(), s.opts.Theme)
 		}),
+		fuzzyfinder.WithHeader(registersHeader(len(regs))),
 		// reloads passed history slice automatically when items appended
 		fuzzyfinder.WithHotReloadLock(mu.RLocker()),
 		fuzzyfinder.WithPromptString(prompt),
+		// go-fuzzyfinder neither reports the typed query nor moves the cursor in multi-select mode,
+		// so a reopened window starts from the command-line query at the newest item, with nothing selected:
+		// a preselection would be returned by the next <enter> instead of the highlighted item
+		fuzzyfinder.WithQuery(s.opts.Query),
 	)
 	if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
 		return nil, err
@@ -3,12 +3,14 @@ package finder
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"testing"
 
 	"github.com/kaliv0/homie/internal/storage"
 )
 
+// mockReader serves pages keyed by the id of the cursor item (0 -> first page).
 type mockReader struct {
 	pages     map[int][]storage.ClipboardItem
 	readErr   error
@@ -16,14 +18,14 @@ type mockReader struct {
 	readCalls chan struct{}
 }
 
-func (m *mockReader) Read(offset, _ int) ([]storage.ClipboardItem, error) {
+func (m *mockReader) ReadAfter(cursor storage.Cursor, _ int) ([]storage.ClipboardItem, error) {
 	if m.readCalls != nil {
 		m.readCalls <- struct{}{}
 	}
 	if m.readErr != nil {
 		return nil, m.readErr
 	}
-	if page, ok := m.pages[offset]; ok {
+	if page, ok := m.pages[cursor.ID]; ok {
 		return page, nil
 	}
 	return nil, nil
@@ -52,9 +54,9 @@ type countingMockReader struct {
 	callCount int
 }
 
-func (c *countingMockReader) Read(offset, limit int) ([]storage.ClipboardItem, error) {
+func (c *countingMockReader) ReadAfter(cursor storage.Cursor, limit int) ([]storage.ClipboardItem, error) {
 	c.callCount++
-	return c.reader.Read(offset, limit)
+	return c.reader.ReadAfter(cursor, limit)
 }
 
 func (c *countingMockReader) Count() (int, error) {
@@ -75,27 +77,13 @@ func waitForReads(t *testing.T, calls <-chan struct{}, n int) {
 type limitsCase struct {
 	name    string
 	pages   map[int][]storage.ClipboardItem
-	total   int
-	offset  int
 	limit   int
 	signals int
-	wantMin int
-	wantMax int
+	reads   int
+	wantLen int
 	initLen int
 }
 
-func readsToWait(tc limitsCase) int {
-	if tc.limit <= 0 || tc.offset >= tc.total || tc.signals <= 0 {
-		return 0
-	}
-
-	maxReads := (tc.total - tc.offset + tc.limit - 1) / tc.limit
-	if tc.signals < maxReads {
-		return tc.signals
-	}
-	return maxReads
-}
-
 // loadChannelFixture is shared test state.
 type loadChannelFixture struct {
 	history  []storage.ClipboardItem
@@ -105,14 +93,14 @@ type loadChannelFixture struct {
 
 // newLoadChannelFixture creates a fixture and starts the load channel goroutine.
 func newLoadChannelFixture(t *testing.T, reader HistoryReader, initHistory []storage.ClipboardItem,
-	offset, limit, total int) *loadChannelFixture {
+	limit int) *loadChannelFixture {
 	t.Helper()
 	ctx := t.Context()
 
 	f := &loadChannelFixture{
 		history: append([]storage.ClipboardItem{}, initHistory...),
 	}
-	f.loadMore = handleLoadChannel(ctx, &f.history, reader, offset, limit, total, &f.wg)
+	f.loadMore = handleLoadChannel(ctx, &f.history, reader, limit, &f.wg)
 
 	t.Cleanup(func() {
 		close(f.loadMore)
@@ -131,12 +119,12 @@ func (f *loadChannelFixture) historyLen() int {
 func TestHandleLoadChannel_LoadsPages(t *testing.T) {
 	reader := newMockReader(
 		map[int][]storage.ClipboardItem{
-			5: {{ID: 1, ClipText: "page2-item1"}, {ID: 2, ClipText: "page2-item2"}},
+			9: {{ID: 8, ClipText: "page2-item1"}, {ID: 7, ClipText: "page2-item2"}},
 		},
 		nil,
-		10,
+		3,
 	)
-	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 0, ClipText: "page1-item1"}}, 0, 5, 10)
+	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 9, ClipText: "page1-item1"}}, 1)
 
 	f.loadMore <- struct{}{}
 	waitForReads(t, reader.readCalls, 1)
@@ -146,15 +134,47 @@ func TestHandleLoadChannel_LoadsPages(t *testing.T) {
 	}
 }
 
-func TestHandleLoadChannel_StopsAtTotal(t *testing.T) {
+func TestHandleLoadChannel_ReadsAfterLastLoadedItem(t *testing.T) {
+	reader := newMockReader(
+		map[int][]storage.ClipboardItem{
+			8: {{ID: 7, ClipText: "p2-1"}, {ID: 6, ClipText: "p2-2"}},
+			6: {{ID: 3, ClipText: "p3-1"}, {ID: 2, ClipText: "p3-2"}},
+		},
+		nil,
+		6,
+	)
+	init := []storage.ClipboardItem{{ID: 9, ClipText: "p1-1"}, {ID: 8, ClipText: "p1-2"}}
+	f := newLoadChannelFixture(t, reader, init, 2)
+
+	for range 2 {
+		f.loadMore <- struct{}{}
+		waitForReads(t, reader.readCalls, 1)
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	var got []int
+	for _, item := range f.history {
+		got = append(got, item.ID)
+	}
+	if want := []int{9, 8, 7, 6, 3, 2}; !slices.Equal(got, want) {
+		t.Errorf("expected ids %v, got %v", want, got)
+	}
+}
+
+func TestHandleLoadChannel_StopsAfterShortPage(t *testing.T) {
 	reader := newMockReader(map[int][]storage.ClipboardItem{}, nil, 5)
 	countReader := &countingMockReader{reader: reader, callCount: 0}
 
-	f := newLoadChannelFixture(t, countReader, nil, 5, 5, 5)
-	f.loadMore <- struct{}{}
+	history := []storage.ClipboardItem{{ID: 2, ClipText: "a"}, {ID: 1, ClipText: "b"}}
+	var wg sync.WaitGroup
+	loadMore := handleLoadChannel(t.Context(), &history, countReader, 5, &wg)
+	loadMore <- struct{}{}
+	close(loadMore)
+	wg.Wait()
 
 	if countReader.callCount != 0 {
-		t.Errorf("expected 0 reads when offset >= total, got %d", countReader.callCount)
+		t.Errorf("expected 0 reads after a short first page, got %d", countReader.callCount)
 	}
 }
 
@@ -163,7 +183,7 @@ func TestHandleLoadChannel_ContextCancel(t *testing.T) {
 	reader := newMockReader(nil, nil, 100)
 	var history []storage.ClipboardItem
 	var wg sync.WaitGroup
-	loadMore := handleLoadChannel(ctx, &history, reader, 0, 5, 100, &wg)
+	loadMore := handleLoadChannel(ctx, &history, reader, 5, &wg)
 
 	cancel()
 
@@ -173,13 +193,13 @@ func TestHandleLoadChannel_ContextCancel(t *testing.T) {
 
 func TestHandleLoadChannel_ReadError(t *testing.T) {
 	reader := newMockReader(nil, errors.New("db error"), 100)
-	f := newLoadChannelFixture(t, reader, nil, 0, 5, 100)
+	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 1}}, 1)
 
 	f.loadMore <- struct{}{}
 	waitForReads(t, reader.readCalls, 1)
 
-	if n := f.historyLen(); n != 0 {
-		t.Errorf("expected 0 items after read error, got %d", n)
+	if n := f.historyLen(); n != 1 {
+		t.Errorf("expected 1 item after read error, got %d", n)
 	}
 }
 
@@ -187,7 +207,7 @@ func TestHandleLoadChannel_ChannelClose(t *testing.T) {
 	reader := newMockReader(nil, nil, 100)
 	var history []storage.ClipboardItem
 	var wg sync.WaitGroup
-	loadMore := handleLoadChannel(t.Context(), &history, reader, 0, 5, 100, &wg)
+	loadMore := handleLoadChannel(t.Context(), &history, reader, 5, &wg)
 
 	close(loadMore)
 	wg.Wait()
@@ -201,52 +221,51 @@ func TestHandleLoadChannel_Limits(t *testing.T) {
 				1: {{ID: 2, ClipText: "second"}},
 				2: {{ID: 3, ClipText: "third"}},
 			},
-			total: 3, offset: 0, limit: 1, signals: 2, wantMin: 3, wantMax: 3, initLen: 1,
+			limit: 1, signals: 2, reads: 2, wantLen: 3, initLen: 1,
 		},
 		{
 			name: "large limit",
 			pages: map[int][]storage.ClipboardItem{
-				100: {{ID: 2, ClipText: "page2"}},
+				100: {{ID: 101, ClipText: "page2"}},
 			},
-			total: 200, offset: 0, limit: 100, signals: 1, wantMin: 2, wantMax: 2, initLen: 1,
+			limit: 100, signals: 1, reads: 1, wantLen: 101, initLen: 100,
 		},
 		{
-			name:  "zero total",
+			name:  "empty history",
 			pages: map[int][]storage.ClipboardItem{},
-			total: 0, offset: 0, limit: 5, signals: 1, wantMin: 0, wantMax: 0, initLen: 0,
+			limit: 5, signals: 1, reads: 0, wantLen: 0, initLen: 0,
 		},
 		{
-			name:  "offset already at end",
+			name:  "no more items",
 			pages: map[int][]storage.ClipboardItem{},
-			total: 10, offset: 10, limit: 5, signals: 1, wantMin: 0, wantMax: 0, initLen: 0,
+			limit: 5, signals: 2, reads: 1, wantLen: 5, initLen: 5,
 		},
 		{
 			name: "partial page",
 			pages: map[int][]storage.ClipboardItem{
 				5: {{ID: 6, ClipText: "partial-1"}, {ID: 7, ClipText: "partial-2"}},
 			},
-			total: 7, offset: 0, limit: 5, signals: 1, wantMin: 3, wantMax: 3, initLen: 1,
+			limit: 5, signals: 1, reads: 1, wantLen: 7, initLen: 5,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reader := newMockReader(tt.pages, nil, tt.total)
+			reader := newMockReader(tt.pages, nil, 0)
 			init := make([]storage.ClipboardItem, tt.initLen)
 			for i := range init {
 				init[i] = storage.ClipboardItem{ID: i + 1, ClipText: "init"}
 			}
 
-			f := newLoadChannelFixture(t, reader, init, tt.offset, tt.limit, tt.total)
+			f := newLoadChannelFixture(t, reader, init, tt.limit)
 
 			for range tt.signals {
 				f.loadMore <- struct{}{}
 			}
-			waitForReads(t, reader.readCalls, readsToWait(tt))
+			waitForReads(t, reader.readCalls, tt.reads)
 
-			n := f.historyLen()
-			if n < tt.wantMin || n > tt.wantMax {
-				t.Errorf("expected history len in [%d, %d], got %d", tt.wantMin, tt.wantMax, n)
+			if n := f.historyLen(); n != tt.wantLen {
+				t.Errorf("expected history len %d, got %d", tt.wantLen, n)
 			}
 		})
 	}
@@ -255,44 +274,45 @@ func TestHandleLoadChannel_Limits(t *testing.T) {
 func TestHandleLoadChannel_MultipleLoads(t *testing.T) {
 	reader := newMockReader(
 		map[int][]storage.ClipboardItem{
-			5:  {{ID: 6, ClipText: "p2-1"}, {ID: 7, ClipText: "p2-2"}},
-			10: {{ID: 8, ClipText: "p3-1"}},
-			15: {},
+			1: {{ID: 6, ClipText: "p2-1"}},
+			6: {{ID: 8, ClipText: "p3-1"}},
+			8: {},
 		},
 		nil,
-		15,
+		3,
 	)
-	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 1, ClipText: "p1-1"}}, 0, 5, 15)
+	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 1, ClipText: "p1-1"}}, 1)
 
 	f.loadMore <- struct{}{}
 	waitForReads(t, reader.readCalls, 1)
-	if n := f.historyLen(); n != 3 {
-		t.Errorf("after page 2: expected 3 items, got %d", n)
+	if n := f.historyLen(); n != 2 {
+		t.Errorf("after page 2: expected 2 items, got %d", n)
 	}
 
 	f.loadMore <- struct{}{}
 	waitForReads(t, reader.readCalls, 1)
-	if n := f.historyLen(); n != 4 {
-		t.Errorf("after page 3: expected 4 items, got %d", n)
+	if n := f.historyLen(); n != 3 {
+		t.Errorf("after page 3: expected 3 items, got %d", n)
 	}
 
 	f.loadMore <- struct{}{}
-	if n := f.historyLen(); n != 4 {
-		t.Errorf("after empty page: expected 4 items, got %d", n)
+	waitForReads(t, reader.readCalls, 1)
+	if n := f.historyLen(); n != 3 {
+		t.Errorf("after empty page: expected 3 items, got %d", n)
 	}
 }
 
 func TestHandleLoadChannel_RapidSignals(t *testing.T) {
 	reader := newMockReader(
 		map[int][]storage.ClipboardItem{
-			5:  {{ID: 2, ClipText: "p2"}},
-			10: {{ID: 3, ClipText: "p3"}},
-			15: {{ID: 4, ClipText: "p4"}},
+			1: {{ID: 2, ClipText: "p2"}},
+			2: {{ID: 3, ClipText: "p3"}},
+			3: {{ID: 4, ClipText: "p4"}},
 		},
 		nil,
-		20,
+		4,
 	)
-	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 1, ClipText: "init"}}, 0, 5, 20)
+	f := newLoadChannelFixture(t, reader, []storage.ClipboardItem{{ID: 1, ClipText: "init"}}, 1)
 
 	for range 5 {
 		select {
@@ -306,3 +326,52 @@ func TestHandleLoadChannel_RapidSignals(t *testing.T) {
 		t.Errorf("expected at least 2 items after rapid signals, got %d", n)
 	}
 }
+
+func TestShouldPrefetch(t *testing.T) {
+	tests := []struct {
+		i, loaded int
+		want      bool
+	}{
+		{i: -1, loaded: 50, want: true},
+		{i: 0, loaded: 50, want: false},
+		{i: 39, loaded: 50, want: false},
+		{i: 40, loaded: 50, want: true},
+		{i: 49, loaded: 50, want: true},
+		{i: 0, loaded: 5, want: true},
+	}
+	for _, tt := range tests {
+		if got := shouldPrefetch(tt.i, tt.loaded); got != tt.want {
+			t.Errorf("shouldPrefetch(%d, %d) = %v, want %v", tt.i, tt.loaded, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStatus(t *testing.T) {
+	if got := loadStatus(20, 134); got != "loaded 20 of 134" {
+		t.Errorf("unexpected status %q", got)
+	}
+	// items written after the count was taken
+	if got := loadStatus(25, 20); got != "loaded 25 of 25" {
+		t.Errorf("unexpected status %q", got)
+	}
+}
+
+func TestListLabel(t *testing.T) {
+	tests := []struct {
+		item storage.ClipboardItem
+		want string
+	}{
+		{storage.ClipboardItem{ClipText: "hello", ContentType: "text"}, "hello"},
+		{storage.ClipboardItem{ClipText: "https://example.com", ContentType: "url"}, "[url] https://example.com"},
+		{storage.ClipboardItem{ClipText: "#fff", ContentType: "color", Pinned: true}, "★ [color] #fff"},
+		{
+			storage.ClipboardItem{ClipText: "hunter2", ContentType: "text", Tags: []string{"prod", "ticket-123"}, Note: "db login"},
+			"#prod #ticket-123 «db login» hunter2",
+		},
+	}
+	for _, tt := range tests {
+		if got := listLabel(tt.item); got != tt.want {
+			t.Errorf("listLabel(%q) = %q, want %q", tt.item.ClipText, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+
+	"github.com/kaliv0/homie/internal/action"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+const (
+	launchPrompt  = "OPEN / RUN >> "
+	confirmPrompt = "CONFIRM >> "
+)
+
+// launchActions lists the content actions for a single selected item.
+// Shell actions (cd) can't change the calling shell from here; 'homie act --shell' offers them.
+func (s *session) launchActions(items []storage.ClipboardItem) []action.Action {
+	if s.opts.Launcher == nil || len(items) != 1 {
+		return nil
+	}
+	var actions []action.Action
+	for _, a := range s.opts.Launcher.For(action.NewData(items[0])) {
+		if !a.Shell {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// launch picks a content action for item from a submenu and runs it, after confirmation if required.
+// ok is false when a menu was dismissed.
+func (s *session) launch(item storage.ClipboardItem) (bool, error) {
+	items := []storage.ClipboardItem{item}
+	actions := s.launchActions(items)
+	idx, err := fuzzyfinder.Find(
+		actions,
+		func(i int) string {
+			return fmt.Sprintf("%-10s %s", actions[i].Name, actions[i].Description)
+		},
+		fuzzyfinder.WithHeader(menuHeader(items)),
+		fuzzyfinder.WithPromptString(launchPrompt),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	a, d := actions[idx], action.NewData(item)
+	if a.Confirm {
+		cmdLine, err := a.CommandLine(d)
+		if err != nil {
+			return false, err
+		}
+		if ok, err := confirm("run: " + cmdLine); !ok || err != nil {
+			return false, err
+		}
+	}
+	// stdout carries the selection to the shell integration -> show the command output on stderr
+	return true, a.Run(d, os.Stderr)
+}
+
+// confirm asks to go ahead with choice; cancel is listed first.
+func confirm(choice string) (bool, error) {
+	choices := []string{"cancel", choice}
+	idx, err := fuzzyfinder.Find(
+		choices,
+		func(i int) string {
+			return choices[i]
+		},
+		fuzzyfinder.WithPromptString(confirmPrompt),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return idx == 1, nil
+}
@@ -0,0 +1,29 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/kaliv0/homie/internal/action"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func TestLaunchActions(t *testing.T) {
+	launcher, err := action.NewCatalog(nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() failed: %v", err)
+	}
+	s := &session{opts: Options{Launcher: launcher}}
+	dir := storage.ClipboardItem{ID: 1, ClipText: t.TempDir()}
+
+	// cd only works through the shell integration
+	if got := action.Names(s.launchActions([]storage.ClipboardItem{dir})); got != "open" {
+		t.Errorf("expected the directory to be opened only, got %s", got)
+	}
+	url := storage.ClipboardItem{ID: 2, ClipText: "https://example.com"}
+	if got := s.launchActions([]storage.ClipboardItem{dir, url}); got != nil {
+		t.Errorf("expected no actions for several items, got %v", action.Names(got))
+	}
+	if got := (&session{}).launchActions([]storage.ClipboardItem{url}); got != nil {
+		t.Errorf("expected no actions without a launcher, got %v", action.Names(got))
+	}
+}
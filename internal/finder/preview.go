@@ -0,0 +1,209 @@
+package finder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+const (
+	timeLayout   = "2006-01-02 15:04:05"
+	tabSpaces    = "    "
+	gutterSep    = " │ "
+	headerRule   = '─'
+	minBodyWidth = 8
+)
+
+// previewSize returns the text area of the preview window.
+// fuzzyfinder passes the whole terminal size and draws the preview box on the right half,
+// with a border and one space of padding on each side.
+func previewSize(width, height int) (int, int) {
+	return width - width/2 - 5, height - 2
+}
+
+// renderPreview formats item for the preview window: a metadata header followed by
+// the numbered and highlighted body, wrapped to the window width and cut off at its height.
+// status is right-aligned on the first header line when it fits. A nil theme renders plain text.
+func renderPreview(item storage.ClipboardItem, status string, width, height int, now time.Time,
+	theme highlight.Theme) string {
+	stats := textStats(item.ClipText, item.Type()) + ", copied " + plural(max(item.CopyCount, 1), "time")
+	if item.Pinned {
+		stats += ", pinned"
+	}
+	header := []string{
+		fmt.Sprintf("#%d  %s (%s)", item.ID, item.TimeStamp.Local().Format(timeLayout), relativeTime(item.TimeStamp, now)),
+		stats,
+	}
+	if len(item.Tags) > 0 {
+		header = append(header, "tags: #"+strings.Join(item.Tags, " #"))
+	}
+	if item.Note != "" {
+		header = append(header, "note: "+item.Note)
+	}
+	return renderPanel(header, item.ClipText, status, width, height, theme)
+}
+
+// renderRegisterPreview formats a register like a history item, headed by its name.
+func renderRegisterPreview(reg storage.Register, status string, width, height int, now time.Time,
+	theme highlight.Theme) string {
+	header := []string{
+		fmt.Sprintf("\"%s  set %s (%s)", reg.Name, reg.UpdatedAt.Local().Format(timeLayout), relativeTime(reg.UpdatedAt, now)),
+		textStats(reg.Text, content.Classify(reg.Text)),
+	}
+	return renderPanel(header, reg.Text, status, width, height, theme)
+}
+
+// textStats describes size, line count and content type (with the detected language) of text.
+func textStats(text string, kind content.Type) string {
+	desc := string(kind)
+	if lang := content.DetectLanguage(text); lang != content.LangNone && string(lang) != desc {
+		desc += " (" + string(lang) + ")"
+	}
+	return fmt.Sprintf("%s, %s, %s", formatBytes(len(text)), plural(len(splitLines(text)), "line"), desc)
+}
+
+// renderPanel lays out header lines, a rule and the body of text within the preview window.
+func renderPanel(header []string, text, status string, width, height int, theme highlight.Theme) string {
+	cols, rows := previewSize(width, height)
+	if cols <= 0 || rows <= 0 {
+		return ""
+	}
+
+	out := make([]string, 0, rows)
+	for _, h := range header {
+		out = append(out, runewidth.Truncate(h, cols, ""))
+	}
+	if gap := cols - runewidth.StringWidth(out[0]) - runewidth.StringWidth(status); status != "" && gap >= 2 {
+		out[0] += strings.Repeat(" ", gap) + theme.Paint(status, highlight.Muted)
+	}
+	out = append(out, theme.Paint(strings.Repeat(string(headerRule), cols), highlight.Muted))
+	out = append(out, renderBody(splitLines(text), content.DetectLanguage(text), theme, cols, rows-len(out))...)
+	if len(out) > rows {
+		out = out[:rows]
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderBody numbers, highlights and wraps lines into at most rows rows of cols columns.
+// When lines don't fit, the last row reports how many were left out.
+func renderBody(lines []string, lang content.Language, theme highlight.Theme, cols, rows int) []string {
+	if rows <= 0 {
+		return nil
+	}
+
+	gutter := len(strconv.Itoa(len(lines)))
+	textCols := cols - gutter - runewidth.StringWidth(gutterSep)
+	numbered := textCols >= minBodyWidth
+	if !numbered {
+		textCols = cols
+	}
+
+	hl := highlight.New(lang)
+	out := make([]string, 0, rows)
+	lastLine := 0 // line shown in the last row so far
+	for i, line := range lines {
+		for j, part := range wrapSpans(hl.Line(line), textCols) {
+			if len(out) == rows {
+				// swap the last row for a note on the lines that are (partly) cut off
+				more := fmt.Sprintf("… %s more", plural(len(lines)-lastLine, "line"))
+				out[rows-1] = theme.Paint(runewidth.Truncate(more, cols, ""), highlight.Muted)
+				return out
+			}
+			lastLine = i
+			if !numbered {
+				out = append(out, theme.Render(part))
+				continue
+			}
+			num := ""
+			if j == 0 {
+				num = strconv.Itoa(i + 1)
+			}
+			gutterText := fmt.Sprintf("%*s%s", gutter, num, gutterSep)
+			out = append(out, theme.Paint(gutterText, highlight.Muted)+theme.Render(part))
+		}
+	}
+	return out
+}
+
+// wrapSpans splits a line of spans into rows of at most cols display columns.
+func wrapSpans(spans []highlight.Span, cols int) [][]highlight.Span {
+	var (
+		rows [][]highlight.Span
+		row  []highlight.Span
+		w    int
+	)
+	for _, s := range spans {
+		var b strings.Builder
+		for _, r := range s.Text {
+			rw := runewidth.RuneWidth(r)
+			if w+rw > cols && w > 0 {
+				if b.Len() > 0 {
+					row = append(row, highlight.Span{Text: b.String(), Kind: s.Kind})
+					b.Reset()
+				}
+				rows = append(rows, row)
+				row = nil
+				w = 0
+			}
+			b.WriteRune(r)
+			w += rw
+		}
+		if b.Len() > 0 {
+			row = append(row, highlight.Span{Text: b.String(), Kind: s.Kind})
+		}
+	}
+	return append(rows, row)
+}
+
+// splitLines breaks text into display lines: tabs are expanded and carriage returns dropped.
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r", ""), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\t", tabSpaces), "\n")
+}
+
+// relativeTime describes how long ago t was, e.g. "5m ago".
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
+
+// formatBytes renders n in B, KiB or MiB.
+func formatBytes(n int) string {
+	const unit = 1024
+	switch {
+	case n < unit:
+		return fmt.Sprintf("%d B", n)
+	case n < unit*unit:
+		return fmt.Sprintf("%.1f KiB", float64(n)/unit)
+	default:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(unit*unit))
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,243 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// termSize returns a terminal size whose preview text area is cols x rows.
+func termSize(cols, rows int) (int, int) {
+	width := 2 * (cols + 5)
+	return width, rows + 2
+}
+
+func TestPreviewSize(t *testing.T) {
+	width, height := termSize(40, 10)
+	if cols, rows := previewSize(width, height); cols != 40 || rows != 10 {
+		t.Errorf("expected 40x10 preview, got %dx%d", cols, rows)
+	}
+}
+
+func TestRenderPreview_Header(t *testing.T) {
+	now := time.Now()
+	item := storage.ClipboardItem{
+		ID:        42,
+		ClipText:  `{"a": 1}`,
+		TimeStamp: now.Add(-5 * time.Minute),
+		CopyCount: 3,
+	}
+	width, height := termSize(60, 10)
+	lines := strings.Split(renderPreview(item, "", width, height, now, nil), "\n")
+
+	if !strings.HasPrefix(lines[0], "#42  ") || !strings.HasSuffix(lines[0], "(5m ago)") {
+		t.Errorf("unexpected id/time header: %q", lines[0])
+	}
+	if want := "8 B, 1 line, json, copied 3 times"; lines[1] != want {
+		t.Errorf("expected stats header %q, got %q", want, lines[1])
+	}
+	if want := "1 │ " + item.ClipText; lines[3] != want {
+		t.Errorf("expected numbered body %q, got %q", want, lines[3])
+	}
+}
+
+func TestRenderPreview_TagsAndNote(t *testing.T) {
+	item := storage.ClipboardItem{
+		ID:        3,
+		ClipText:  "hunter2",
+		TimeStamp: time.Now(),
+		Tags:      []string{"prod", "ticket-123"},
+		Note:      "db login",
+	}
+	width, height := termSize(60, 10)
+	lines := strings.Split(renderPreview(item, "", width, height, time.Now(), nil), "\n")
+
+	if lines[2] != "tags: #prod #ticket-123" || lines[3] != "note: db login" {
+		t.Errorf("expected tags and note in the header, got %q", lines[:4])
+	}
+	if want := "1 │ hunter2"; lines[5] != want {
+		t.Errorf("expected body %q after the header, got %q", want, lines[5])
+	}
+}
+
+func TestRenderPreview_Status(t *testing.T) {
+	item := storage.ClipboardItem{ID: 7, ClipText: "text", TimeStamp: time.Now()}
+
+	width, height := termSize(60, 10)
+	first, _, _ := strings.Cut(renderPreview(item, "loaded 20 of 134", width, height, time.Now(), nil), "\n")
+	if !strings.HasPrefix(first, "#7  ") || !strings.HasSuffix(first, " loaded 20 of 134") {
+		t.Errorf("expected status right-aligned in %q", first)
+	}
+	if w := runewidth.StringWidth(first); w != 60 {
+		t.Errorf("expected header to fill 60 columns, got %d", w)
+	}
+
+	// no room -> the status is left out
+	width, height = termSize(30, 10)
+	first, _, _ = strings.Cut(renderPreview(item, "loaded 20 of 134", width, height, time.Now(), nil), "\n")
+	if strings.Contains(first, "loaded") {
+		t.Errorf("expected no status in a narrow header, got %q", first)
+	}
+}
+
+func TestRenderPreview_FitsWindow(t *testing.T) {
+	item := storage.ClipboardItem{
+		ID:        1,
+		ClipText:  strings.Repeat(strings.Repeat("x", 100)+"\n", 30),
+		TimeStamp: time.Now(),
+	}
+	cols, rows := 30, 12
+	width, height := termSize(cols, rows)
+	lines := strings.Split(renderPreview(item, "", width, height, time.Now(), nil), "\n")
+
+	if len(lines) != rows {
+		t.Fatalf("expected %d rows, got %d", rows, len(lines))
+	}
+	for _, l := range lines {
+		if w := runewidth.StringWidth(l); w > cols {
+			t.Errorf("row wider than %d columns (%d): %q", cols, w, l)
+		}
+	}
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "… ") || !strings.HasSuffix(last, "lines more") {
+		t.Errorf("expected truncation note in last row, got %q", last)
+	}
+}
+
+func TestRenderPreview_LanguageInHeader(t *testing.T) {
+	item := storage.ClipboardItem{ID: 1, ClipText: "package main\n\nfunc main() {}", TimeStamp: time.Now()}
+	width, height := termSize(60, 10)
+	lines := strings.Split(renderPreview(item, "", width, height, time.Now(), nil), "\n")
+
+	if !strings.Contains(lines[1], "code (go)") {
+		t.Errorf("expected type with language in header, got %q", lines[1])
+	}
+}
+
+func TestRenderBody_Highlighted(t *testing.T) {
+	theme := highlight.LookupTheme(highlight.DefaultTheme)
+	if theme == nil {
+		t.Skip("colors disabled by NO_COLOR")
+	}
+	lines := []string{`return "a long string that wraps"`}
+	cols := 20
+
+	got := renderBody(lines, content.LangGo, theme, cols, 5)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 wrapped rows, got %d: %q", len(got), got)
+	}
+	if !strings.Contains(got[0], "\x1b[") {
+		t.Errorf("expected SGR sequences in %q", got[0])
+	}
+	// a string wrapped across rows is colored on each row
+	if !strings.HasSuffix(got[1], "\x1b[0m") {
+		t.Errorf("expected continued string to be colored, got %q", got[1])
+	}
+
+	plain := renderBody(lines, content.LangGo, nil, cols, 5)
+	for i, row := range plain {
+		if strip := stripSGR(got[i]); strip != row {
+			t.Errorf("row %d: colored text %q differs from plain %q", i, strip, row)
+		}
+	}
+}
+
+// stripSGR removes SGR escape sequences.
+func stripSGR(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "\x1b[")
+		if start < 0 {
+			return b.String() + s
+		}
+		b.WriteString(s[:start])
+		end := strings.IndexByte(s[start:], 'm')
+		s = s[start+end+1:]
+	}
+}
+
+func TestRenderBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		cols  int
+		rows  int
+		want  []string
+	}{
+		{
+			name:  "numbered",
+			lines: []string{"a", "b"},
+			cols:  20, rows: 5,
+			want: []string{"1 │ a", "2 │ b"},
+		},
+		{
+			name:  "wrapped line keeps number on first row",
+			lines: []string{"abcdefghijkl"},
+			cols:  12, rows: 5,
+			want: []string{"1 │ abcdefgh", "  │ ijkl"},
+		},
+		{
+			name:  "cut off",
+			lines: []string{"a", "b", "c", "d"},
+			cols:  20, rows: 2,
+			want: []string{"1 │ a", "… 3 lines more"},
+		},
+		{
+			name:  "too narrow for numbers",
+			lines: []string{"abcdef"},
+			cols:  4, rows: 5,
+			want: []string{"abcd", "ef"},
+		},
+		{
+			name:  "empty",
+			lines: nil,
+			cols:  20, rows: 5,
+			want: nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := renderBody(tc.lines, content.LangNone, nil, tc.cols, tc.rows)
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("renderBody() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{30 * time.Second, "30s ago"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tc := range tests {
+		if got := relativeTime(now.Add(-tc.ago), now); got != tc.want {
+			t.Errorf("relativeTime(-%v) = %q, want %q", tc.ago, got, tc.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int]string{
+		12:          "12 B",
+		2048:        "2.0 KiB",
+		3 * 1 << 20: "3.0 MiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package finder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// registerNameWidth aligns the text of registers with short names in the list.
+const registerNameWidth = 10
+
+// prependRegisters puts regs as a section before the loaded history,
+// each one turned into a pseudo-item by registerItem.
+func prependRegisters(history *[]storage.ClipboardItem, regs []storage.Register) {
+	items := make([]storage.ClipboardItem, 0, len(regs))
+	for _, reg := range regs {
+		items = append(items, registerItem(reg))
+	}
+	mu.Lock()
+	*history = slices.Insert(*history, 0, items...)
+	mu.Unlock()
+}
+
+// registersHeader introduces the sections of the history window, empty without registers.
+func registersHeader(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s first, then the history", plural(n, "register"))
+}
+
+// registerLabel is the list entry of a register: its name, padded, before the first line of its text.
+func registerLabel(reg storage.Register) string {
+	first, _, _ := strings.Cut(reg.Text, "\n")
+	return runewidth.FillRight(`"`+reg.Name, registerNameWidth) + " " + first
+}
+
+// registerItem lets the joiner and the templates treat a register like a history item.
+// Its ID is 0, which no history item has (see isRegister), and its note is the name of the register.
+func registerItem(reg storage.Register) storage.ClipboardItem {
+	return storage.ClipboardItem{ClipText: reg.Text, TimeStamp: reg.UpdatedAt, CopyCount: 1, Note: reg.Name}
+}
+
+// itemName names a selected item in headers and diffs: #id for a history item, "name for a register.
+func itemName(item storage.ClipboardItem) string {
+	if isRegister(item) {
+		return `"` + item.Note
+	}
+	return fmt.Sprintf("#%d", item.ID)
+}
+
+// isRegister reports whether item is a register from the section before the history.
+func isRegister(item storage.ClipboardItem) bool {
+	return item.ID == 0
+}
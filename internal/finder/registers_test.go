@@ -0,0 +1,59 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func TestRegisterLabel(t *testing.T) {
+	tests := []struct {
+		reg  storage.Register
+		want string
+	}{
+		{storage.Register{Name: "a", Text: "HOM-42"}, `"a         HOM-42`},
+		{storage.Register{Name: "deploy-cmd", Text: "make deploy\nmake verify"}, `"deploy-cmd make deploy`},
+	}
+	for _, tt := range tests {
+		if got := registerLabel(tt.reg); got != tt.want {
+			t.Errorf("registerLabel(%q) = %q, want %q", tt.reg.Name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderRegisterPreview(t *testing.T) {
+	now := time.Now()
+	reg := storage.Register{Name: "ticket", Text: "https://tracker.example/HOM-42", UpdatedAt: now.Add(-2 * time.Hour)}
+	width, height := termSize(60, 10)
+	lines := strings.Split(renderRegisterPreview(reg, "", width, height, now, nil), "\n")
+
+	if !strings.HasPrefix(lines[0], `"ticket  set `) || !strings.HasSuffix(lines[0], "(2h ago)") {
+		t.Errorf("unexpected first header line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "url") {
+		t.Errorf("expected the content type in the header, got %q", lines[1])
+	}
+}
+
+func TestPrependRegisters(t *testing.T) {
+	history := []storage.ClipboardItem{{ID: 2, ClipText: "two"}, {ID: 1, ClipText: "one"}}
+	prependRegisters(&history, []storage.Register{{Name: "a", Text: "reg-a"}, {Name: "b", Text: "reg-b"}})
+
+	if len(history) != 4 || history[0].ClipText != "reg-a" || history[1].ClipText != "reg-b" || history[2].ID != 2 {
+		t.Fatalf("expected the registers first, then the history, got %+v", history)
+	}
+	if !isRegister(history[1]) || isRegister(history[2]) {
+		t.Error("expected only the register pseudo-items to be registers")
+	}
+}
+
+func TestRegistersHeader(t *testing.T) {
+	if got := registersHeader(0); got != "" {
+		t.Errorf("expected no header without registers, got %q", got)
+	}
+	if got := registersHeader(2); got != "2 registers first, then the history" {
+		t.Errorf("unexpected header %q", got)
+	}
+}
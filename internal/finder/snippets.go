@@ -0,0 +1,73 @@
+package finder
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/kaliv0/homie/internal/highlight"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+const (
+	snippetPrompt = "SNIPPET >> "
+	// snippetNameWidth aligns the text of snippets with short names in the list.
+	snippetNameWidth = 16
+)
+
+// PickSnippet presents snippets in a fuzzy finder; tags are part of the searchable list entries.
+// ok is false when nothing was selected.
+func PickSnippet(snippets []storage.Snippet, query string, theme highlight.Theme) (storage.Snippet, bool, error) {
+	idx, err := fuzzyfinder.Find(
+		snippets,
+		func(i int) string {
+			return snippetLabel(snippets[i])
+		},
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 {
+				return ""
+			}
+			return renderSnippetPreview(snippets[i], width, height, theme)
+		}),
+		fuzzyfinder.WithHeader(fmt.Sprintf("%d snippets", len(snippets))),
+		fuzzyfinder.WithPromptString(snippetPrompt),
+		fuzzyfinder.WithQuery(query),
+	)
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return storage.Snippet{}, false, nil
+	}
+	if err != nil {
+		return storage.Snippet{}, false, err
+	}
+	return snippets[idx], true, nil
+}
+
+// snippetLabel is the list entry of a snippet: name, #tags and the first line of its text.
+func snippetLabel(s storage.Snippet) string {
+	var b strings.Builder
+	b.WriteString(runewidth.FillRight(s.Name, snippetNameWidth))
+	for _, tag := range s.Tags {
+		b.WriteString(" #" + tag)
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(s.Text), "\n")
+	b.WriteString("  " + first)
+	return b.String()
+}
+
+// renderSnippetPreview shows the unexpanded text of a snippet under its name and origin.
+func renderSnippetPreview(s storage.Snippet, width, height int, theme highlight.Theme) string {
+	origin := "saved"
+	if s.Source != "" {
+		origin = "from " + filepath.Base(s.Source)
+	}
+	name := s.Name
+	if len(s.Tags) > 0 {
+		name += "  #" + strings.Join(s.Tags, " #")
+	}
+	header := []string{name, fmt.Sprintf("%s, %s", origin, plural(len(splitLines(s.Text)), "line"))}
+	return renderPanel(header, s.Text, "", width, height, theme)
+}
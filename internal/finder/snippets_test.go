@@ -0,0 +1,28 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+func TestSnippetLabel(t *testing.T) {
+	s := storage.Snippet{Name: "deploy", Tags: []string{"k8s", "ops"}, Text: "\nkubectl apply -f app.yaml\nkubectl get pods"}
+	if got, want := snippetLabel(s), "deploy           #k8s #ops  kubectl apply -f app.yaml"; got != want {
+		t.Errorf("snippetLabel() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSnippetPreview(t *testing.T) {
+	s := storage.Snippet{Name: "greet", Tags: []string{"mail"}, Text: "Hi {{prompt \"name\"}},\n", Source: "/home/x/snippets/mail.yaml"}
+	width, height := termSize(60, 10)
+	lines := strings.Split(renderSnippetPreview(s, width, height, nil), "\n")
+
+	if lines[0] != "greet  #mail" || lines[1] != "from mail.yaml, 1 line" {
+		t.Errorf("unexpected header %q", lines[:2])
+	}
+	if !strings.Contains(lines[3], `{{prompt "name"}}`) {
+		t.Errorf("expected the unexpanded text, got %q", lines[3])
+	}
+}
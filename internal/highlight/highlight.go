@@ -0,0 +1,202 @@
+package highlight
+
+import (
+	"strings"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+// Kind classifies a highlighted piece of text.
+type Kind int
+
+const (
+	Plain Kind = iota
+	Keyword
+	String
+	Number
+	Comment
+	// Key marks object keys and shell variables.
+	Key
+	Added
+	Removed
+	// Meta marks diff headers and hunk ranges.
+	Meta
+	// Muted is used for decorations such as line numbers.
+	Muted
+)
+
+// Span is a run of text of a single kind.
+type Span struct {
+	Text string
+	Kind Kind
+}
+
+// Highlighter tokenizes the lines of one clipboard entry.
+// It keeps state between lines, so block comments and raw strings spanning lines are colored as a whole.
+type Highlighter struct {
+	lang    content.Language
+	syn     *syntax
+	inBlock bool
+	inQuote byte
+}
+
+// New returns a highlighter for lang (LangNone yields plain spans).
+func New(lang content.Language) *Highlighter {
+	return &Highlighter{lang: lang, syn: syntaxes[lang]}
+}
+
+// Line splits the next line of the entry into spans.
+func (h *Highlighter) Line(line string) []Span {
+	if line == "" {
+		return nil
+	}
+	if h.lang == content.LangDiff {
+		return []Span{{line, diffKind(line)}}
+	}
+	if h.syn == nil {
+		return []Span{{line, Plain}}
+	}
+	return h.tokenize(line)
+}
+
+func diffKind(line string) Kind {
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "),
+		strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "), strings.HasPrefix(line, "@@"):
+		return Meta
+	case strings.HasPrefix(line, "+"):
+		return Added
+	case strings.HasPrefix(line, "-"):
+		return Removed
+	default:
+		return Plain
+	}
+}
+
+func (h *Highlighter) tokenize(line string) []Span {
+	var spans spanList
+	syn := h.syn
+	i := 0
+	for i < len(line) {
+		switch {
+		case h.inBlock:
+			end := strings.Index(line[i:], syn.blockEnd)
+			if end < 0 {
+				spans.add(line[i:], Comment)
+				return spans
+			}
+			end += i + len(syn.blockEnd)
+			spans.add(line[i:end], Comment)
+			h.inBlock = false
+			i = end
+
+		case h.inQuote != 0:
+			end := closingQuote(line, i, h.inQuote, syn.escapes(h.inQuote))
+			if end < 0 {
+				spans.add(line[i:], String)
+				return spans
+			}
+			spans.add(line[i:end], String)
+			h.inQuote = 0
+			i = end
+
+		case syn.lineCommentAt(line, i):
+			spans.add(line[i:], Comment)
+			return spans
+
+		case syn.blockStart != "" && strings.HasPrefix(line[i:], syn.blockStart):
+			h.inBlock = true
+			spans.add(syn.blockStart, Comment)
+			i += len(syn.blockStart)
+
+		case strings.IndexByte(syn.quotes, line[i]) >= 0:
+			q := line[i]
+			end := closingQuote(line, i+1, q, syn.escapes(q))
+			if end < 0 {
+				if strings.IndexByte(syn.multiline, q) >= 0 {
+					h.inQuote = q
+				}
+				end = len(line)
+			}
+			spans.add(line[i:end], syn.keyOr(line, end, String))
+			i = end
+
+		case syn.variables && line[i] == '$' && i+1 < len(line) && (isIdentStart(line[i+1]) || line[i+1] == '{'):
+			end := variableEnd(line, i+1)
+			spans.add(line[i:end], Key)
+			i = end
+
+		case isDigit(line[i]) && (i == 0 || !isIdentPart(line[i-1])):
+			end := i + 1
+			for end < len(line) && (isIdentPart(line[end]) || line[end] == '.') {
+				end++
+			}
+			spans.add(line[i:end], Number)
+			i = end
+
+		case isIdentStart(line[i]):
+			end := i + 1
+			for end < len(line) && (isIdentPart(line[end]) || strings.IndexByte(syn.identExtra, line[end]) >= 0) {
+				end++
+			}
+			spans.add(line[i:end], syn.keyOr(line, end, syn.wordKind(line[i:end])))
+			i = end
+
+		default:
+			spans.add(line[i:i+1], Plain)
+			i++
+		}
+	}
+	return spans
+}
+
+// closingQuote returns the index after the quote q closing a string that starts at from (-1 if unterminated).
+func closingQuote(line string, from int, q byte, escapes bool) int {
+	for i := from; i < len(line); i++ {
+		switch {
+		case escapes && line[i] == '\\':
+			i++
+		case line[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// variableEnd returns the end of a shell variable name ($NAME or ${...}) starting at from.
+func variableEnd(line string, from int) int {
+	if line[from] == '{' {
+		if end := strings.IndexByte(line[from:], '}'); end >= 0 {
+			return from + end + 1
+		}
+		return len(line)
+	}
+	end := from
+	for end < len(line) && isIdentPart(line[end]) {
+		end++
+	}
+	return end
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// spanList merges adjacent spans of the same kind.
+type spanList []Span
+
+func (s *spanList) add(text string, kind Kind) {
+	if n := len(*s); n > 0 && (*s)[n-1].Kind == kind {
+		(*s)[n-1].Text += text
+		return
+	}
+	*s = append(*s, Span{text, kind})
+}
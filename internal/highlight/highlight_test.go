@@ -0,0 +1,79 @@
+package highlight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+var kindNames = map[Kind]string{
+	Plain: "plain", Keyword: "kw", String: "str", Number: "num", Comment: "com",
+	Key: "key", Added: "add", Removed: "del", Meta: "meta", Muted: "muted",
+}
+
+// dump renders the non-plain spans of lines as "kind(text)" for compact assertions.
+func dump(lang content.Language, lines ...string) string {
+	h := New(lang)
+	var out []string
+	for _, line := range lines {
+		for _, s := range h.Line(line) {
+			if s.Kind != Plain {
+				out = append(out, fmt.Sprintf("%s(%s)", kindNames[s.Kind], s.Text))
+			}
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  content.Language
+		lines []string
+		want  string
+	}{
+		{"plain text", content.LangNone, []string{"func x"}, ""},
+		{"go keywords and strings", content.LangGo, []string{`return fmt.Sprintf("%d", 42) // done`},
+			`kw(return) str("%d") num(42) com(// done)`},
+		{"go escaped quote", content.LangGo, []string{`s := "a\"b"`}, `str("a\"b")`},
+		{"go block comment spans lines", content.LangGo, []string{"/* start", "end */ var x"},
+			"com(/* start) com(end */) kw(var)"},
+		{"go raw string spans lines", content.LangGo, []string{"q := `select", "from`"},
+			"str(`select) str(from`)"},
+		{"identifier digits are not numbers", content.LangGo, []string{"x2 := v1"}, ""},
+		{"json keys", content.LangJSON, []string{`{"a": true, "b": "c"}`},
+			`key("a") kw(true) key("b") str("c")`},
+		{"yaml keys and comments", content.LangYAML, []string{"max-size: 500 # entries"},
+			"key(max-size) num(500) com(# entries)"},
+		{"yaml url value is not a key", content.LangYAML, []string{"url: http://x"}, "key(url)"},
+		{"sql is case-insensitive", content.LangSQL, []string{"Select * FROM t -- all"},
+			"kw(Select) kw(FROM) com(-- all)"},
+		{"sql quotes have no escapes", content.LangSQL, []string{`where p = 'C:\'`}, `kw(where) str('C:\')`},
+		{"shell variables", content.LangShell, []string{`echo "$HOME" ${USER} # hi`},
+			`str("$HOME") key(${USER}) com(# hi)`},
+		{"shell hash inside word", content.LangShell, []string{"echo a#b"}, ""},
+		{"python", content.LangPython, []string{"def f(): return None"}, "kw(def) kw(return) kw(None)"},
+		{"diff", content.LangDiff, []string{"--- a/x", "@@ -1 +1 @@", "-old", "+new", " same"},
+			"meta(--- a/x) meta(@@ -1 +1 @@) del(-old) add(+new)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dump(tc.lang, tc.lines...); got != tc.want {
+				t.Errorf("got  %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLine_KeepsText(t *testing.T) {
+	line := `if err := db.Get(&x, "SELECT 1"); err != nil { /* no */ return err }`
+	var b strings.Builder
+	for _, s := range New(content.LangGo).Line(line) {
+		b.WriteString(s.Text)
+	}
+	if b.String() != line {
+		t.Errorf("spans don't reassemble the line:\ngot  %q\nwant %q", b.String(), line)
+	}
+}
@@ -0,0 +1,138 @@
+package highlight
+
+import (
+	"strings"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+// syntax describes the lexical rules of a language.
+type syntax struct {
+	lineComments []string
+	blockStart   string
+	blockEnd     string
+	quotes       string
+	// multiline lists quotes whose strings may span lines.
+	multiline string
+	// raw lists quotes without backslash escapes.
+	raw string
+	// identExtra lists bytes allowed inside identifiers besides letters, digits and '_'.
+	identExtra string
+	keywords   map[string]bool
+	// foldCase makes keywords case-insensitive.
+	foldCase bool
+	// keys colors identifiers and strings followed by ':' as keys.
+	keys bool
+	// keySpace requires a blank (or the line end) after the key colon.
+	keySpace bool
+	// variables colors $NAME and ${...} as keys.
+	variables bool
+}
+
+var syntaxes = map[content.Language]*syntax{
+	content.LangGo: {
+		lineComments: []string{"//"},
+		blockStart:   "/*",
+		blockEnd:     "*/",
+		quotes:       "\"'`",
+		multiline:    "`",
+		raw:          "`",
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if " +
+			"import interface map package range return select struct switch type var true false nil iota"),
+	},
+	content.LangJSON: {
+		quotes:   `"`,
+		keywords: words("true false null"),
+		keys:     true,
+	},
+	content.LangYAML: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		raw:          "'",
+		identExtra:   "-.",
+		keywords:     words("true false null yes no on off"),
+		keys:         true,
+		keySpace:     true,
+	},
+	content.LangSQL: {
+		lineComments: []string{"--"},
+		blockStart:   "/*",
+		blockEnd:     "*/",
+		quotes:       `'"`,
+		raw:          `'"`,
+		keywords: words("select from where insert into values update set delete create table index view alter " +
+			"drop join left right inner outer cross on as and or not null is in like order by group having limit " +
+			"offset distinct union all case when then else end with primary key foreign default references " +
+			"exists between returning asc desc count sum avg min max"),
+		foldCase: true,
+	},
+	content.LangShell: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		raw:          "'",
+		identExtra:   "-",
+		keywords: words("if then else elif fi for while until do done case esac in function return " +
+			"export local readonly unset source"),
+		variables: true,
+	},
+	content.LangPython: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		keywords: words("def class return if elif else for while in import from as with try except finally " +
+			"raise pass break continue lambda yield None True False and or not is global nonlocal assert del " +
+			"async await"),
+	},
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+// lineCommentAt reports whether a line comment starts at i.
+// '#' only starts a comment at the beginning of a word, so "$#" or "a#b" stay code.
+func (s *syntax) lineCommentAt(line string, i int) bool {
+	for _, c := range s.lineComments {
+		if !strings.HasPrefix(line[i:], c) {
+			continue
+		}
+		if c == "#" && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (s *syntax) escapes(q byte) bool {
+	return strings.IndexByte(s.raw, q) < 0
+}
+
+func (s *syntax) wordKind(word string) Kind {
+	if s.foldCase {
+		word = strings.ToLower(word)
+	}
+	if s.keywords[word] {
+		return Keyword
+	}
+	return Plain
+}
+
+// keyOr returns Key when the token ending at end is followed by ':' in a language with keys, else kind.
+func (s *syntax) keyOr(line string, end int, kind Kind) Kind {
+	if !s.keys {
+		return kind
+	}
+	rest := strings.TrimLeft(line[end:], " \t")
+	if !strings.HasPrefix(rest, ":") {
+		return kind
+	}
+	// "http://x" is not a key
+	if after := rest[1:]; s.keySpace && after != "" && after[0] != ' ' && after[0] != '\t' {
+		return kind
+	}
+	return Key
+}
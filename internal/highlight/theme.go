@@ -0,0 +1,91 @@
+package highlight
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultTheme is used when the theme key is not set.
+const DefaultTheme = "dark"
+
+const (
+	noColorEnv = "NO_COLOR"
+	sgrReset   = "\x1b[0m"
+)
+
+// Theme maps span kinds to SGR parameters, e.g. "1;35" for bold magenta.
+// A nil theme renders plain text.
+type Theme map[Kind]string
+
+var themes = map[string]Theme{
+	"dark": {
+		Keyword: "1;35",
+		String:  "32",
+		Number:  "33",
+		Comment: "90",
+		Key:     "36",
+		Added:   "32",
+		Removed: "31",
+		Meta:    "1;34",
+		Muted:   "90",
+	},
+	"light": {
+		Keyword: "1;34",
+		String:  "32",
+		Number:  "31",
+		Comment: "2;3",
+		Key:     "35",
+		Added:   "32",
+		Removed: "31",
+		Meta:    "1;36",
+		Muted:   "2",
+	},
+	"none": nil,
+}
+
+// ThemeNames lists the built-in themes.
+func ThemeNames() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// ColorEnabled reports whether colored output is allowed (see https://no-color.org).
+func ColorEnabled() bool {
+	return os.Getenv(noColorEnv) == ""
+}
+
+// LookupTheme returns the named theme, or nil when colors are disabled or the name is unknown.
+func LookupTheme(name string) Theme {
+	if !ColorEnabled() {
+		return nil
+	}
+	return NamedTheme(name)
+}
+
+// NamedTheme returns the named theme regardless of NO_COLOR, e.g. for an explicit --color=always;
+// nil when the name is unknown.
+func NamedTheme(name string) Theme {
+	if name == "" {
+		name = DefaultTheme
+	}
+	return themes[name]
+}
+
+// Render joins spans, wrapping each styled one in SGR escape sequences.
+func (t Theme) Render(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(t.Paint(s.Text, s.Kind))
+	}
+	return b.String()
+}
+
+// Paint styles text as kind.
+func (t Theme) Paint(text string, kind Kind) string {
+	sgr, ok := t[kind]
+	if !ok || sgr == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + sgr + "m" + text + sgrReset
+}
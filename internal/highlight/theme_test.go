@@ -0,0 +1,54 @@
+package highlight
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookupTheme(t *testing.T) {
+	t.Setenv(noColorEnv, "")
+
+	if LookupTheme("") == nil {
+		t.Error("expected default theme for empty name")
+	}
+	if LookupTheme("light") == nil {
+		t.Error("expected light theme")
+	}
+	if LookupTheme("none") != nil {
+		t.Error("expected nil theme for 'none'")
+	}
+	if LookupTheme("unknown") != nil {
+		t.Error("expected nil theme for unknown name")
+	}
+}
+
+func TestLookupTheme_NoColor(t *testing.T) {
+	t.Setenv(noColorEnv, "1")
+
+	if ColorEnabled() {
+		t.Error("expected colors disabled with NO_COLOR set")
+	}
+	if LookupTheme(DefaultTheme) != nil {
+		t.Error("expected nil theme with NO_COLOR set")
+	}
+	if NamedTheme(DefaultTheme) == nil {
+		t.Error("expected NamedTheme to ignore NO_COLOR")
+	}
+}
+
+func TestThemeNames(t *testing.T) {
+	if got, want := ThemeNames(), []string{"dark", "light", "none"}; !slices.Equal(got, want) {
+		t.Errorf("ThemeNames() = %v, want %v", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	spans := []Span{{"return", Keyword}, {" x", Plain}}
+
+	if got, want := Theme(nil).Render(spans), "return x"; got != want {
+		t.Errorf("nil theme: got %q, want %q", got, want)
+	}
+	if got, want := themes["dark"].Render(spans), "\x1b[1;35mreturn\x1b[0m x"; got != want {
+		t.Errorf("dark theme: got %q, want %q", got, want)
+	}
+}
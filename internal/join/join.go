@@ -0,0 +1,175 @@
+// Package join combines the multi-selected history items into a single text.
+package join
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/kaliv0/homie/internal/render"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// Order arranges the selected items before joining.
+type Order string
+
+const (
+	// OrderSelection keeps the order in which the items were selected.
+	OrderSelection Order = "selection"
+	// OrderChronological puts the oldest item first.
+	OrderChronological Order = "chronological"
+	// OrderReverse puts the newest item first.
+	OrderReverse Order = "reverse"
+)
+
+// Orders lists the supported orders.
+var Orders = []Order{OrderSelection, OrderChronological, OrderReverse}
+
+// Options configures how items are joined.
+type Options struct {
+	// Separator goes between items; escapes are resolved by ParseSeparator.
+	Separator string
+	Order     Order
+	// Template is a text/template executed per item (see render.Record for the fields).
+	Template string
+	// Quote shell-quotes the text of each item.
+	Quote bool
+}
+
+// Joiner joins clipboard items.
+type Joiner struct {
+	opts Options
+	tmpl *template.Template
+}
+
+// New validates opts and returns a joiner; an empty order means OrderSelection.
+func New(opts Options) (*Joiner, error) {
+	if opts.Order == "" {
+		opts.Order = OrderSelection
+	}
+	if !slices.Contains(Orders, opts.Order) {
+		return nil, fmt.Errorf("unknown order %q, choose between: %s", opts.Order, orderNames())
+	}
+	sep, err := ParseSeparator(opts.Separator)
+	if err != nil {
+		return nil, err
+	}
+	opts.Separator = sep
+
+	j := &Joiner{opts: opts}
+	if opts.Template != "" {
+		funcs := maps.Clone(render.TemplateFuncs)
+		funcs["quote"] = Quote
+		if j.tmpl, err = template.New("join").Funcs(funcs).Parse(opts.Template); err != nil {
+			return nil, fmt.Errorf("invalid join template: %w", err)
+		}
+	}
+	return j, nil
+}
+
+// Join arranges items by the configured order and joins them.
+func (j *Joiner) Join(items []storage.ClipboardItem) (string, error) {
+	parts, err := j.Parts(items)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(parts, j.opts.Separator), nil
+}
+
+// Parts arranges items by the configured order and formats each of them, without joining them.
+func (j *Joiner) Parts(items []storage.ClipboardItem) ([]string, error) {
+	items = slices.Clone(items)
+	switch j.opts.Order {
+	case OrderChronological:
+		slices.SortStableFunc(items, compareAge)
+	case OrderReverse:
+		slices.SortStableFunc(items, func(a, b storage.ClipboardItem) int { return compareAge(b, a) })
+	}
+
+	parts := make([]string, 0, len(items))
+	var b strings.Builder
+	for _, item := range items {
+		rec := render.NewRecord(item)
+		if j.opts.Quote {
+			rec.Text = Quote(rec.Text)
+		}
+		if j.tmpl == nil {
+			parts = append(parts, rec.Text)
+			continue
+		}
+		b.Reset()
+		if err := j.tmpl.Execute(&b, rec); err != nil {
+			return nil, fmt.Errorf("failed to execute join template (id=%d): %w", item.ID, err)
+		}
+		parts = append(parts, b.String())
+	}
+	return parts, nil
+}
+
+// compareAge orders items from the oldest to the newest.
+func compareAge(a, b storage.ClipboardItem) int {
+	return cmp.Or(a.TimeStamp.Compare(b.TimeStamp), cmp.Compare(a.ID, b.ID))
+}
+
+// ParseSeparator resolves the escapes \n, \t, \r, \0 and \\ in sep.
+func ParseSeparator(sep string) (string, error) {
+	if !strings.Contains(sep, `\`) {
+		return sep, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(sep); i++ {
+		if sep[i] != '\\' {
+			b.WriteByte(sep[i])
+			continue
+		}
+		if i == len(sep)-1 {
+			return "", fmt.Errorf("invalid separator %q: trailing backslash", sep)
+		}
+		i++
+		switch sep[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			return "", fmt.Errorf(`invalid separator %q: unknown escape \%c (use \n, \t, \r, \0 or \\)`, sep, sep[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// Quote returns s as a single POSIX shell word; safe words are left as they are.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, unsafeRune) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func unsafeRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+=:,./_-", r)
+}
+
+func orderNames() string {
+	names := make([]string, 0, len(Orders))
+	for _, o := range Orders {
+		names = append(names, string(o))
+	}
+	return strings.Join(names, ", ")
+}
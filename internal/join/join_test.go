@@ -0,0 +1,132 @@
+package join
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+var base = time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+// selected is in selection order: the middle item first, then the newest, then the oldest.
+var selected = []storage.ClipboardItem{
+	{ID: 2, ClipText: "my file.txt", TimeStamp: base.Add(time.Minute)},
+	{ID: 3, ClipText: "it's", TimeStamp: base.Add(2 * time.Minute)},
+	{ID: 1, ClipText: "/tmp/a", TimeStamp: base},
+}
+
+func join(t *testing.T, opts Options) string {
+	t.Helper()
+	j, err := New(opts)
+	if err != nil {
+		t.Fatalf("New(%+v) failed: %v", opts, err)
+	}
+	out, err := j.Join(selected)
+	if err != nil {
+		t.Fatalf("Join() failed: %v", err)
+	}
+	return out
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"space", Options{Separator: " "}, "my file.txt it's /tmp/a"},
+		{"newline escape", Options{Separator: `\n`}, "my file.txt\nit's\n/tmp/a"},
+		{"nul escape", Options{Separator: `\0`}, "my file.txt\x00it's\x00/tmp/a"},
+		{"chronological", Options{Separator: ",", Order: OrderChronological}, "/tmp/a,my file.txt,it's"},
+		{"reverse", Options{Separator: ",", Order: OrderReverse}, "it's,my file.txt,/tmp/a"},
+		{"quote", Options{Separator: " ", Quote: true}, `'my file.txt' 'it'\''s' /tmp/a`},
+		{"template", Options{Separator: " ", Template: `"{{.Text}}"`}, `"my file.txt" "it's" "/tmp/a"`},
+		{"template with quote", Options{Separator: "\n", Template: `cp {{.Text}} /dst # {{.ID}}`, Quote: true},
+			"cp 'my file.txt' /dst # 2\ncp 'it'\\''s' /dst # 3\ncp /tmp/a /dst # 1"},
+		{"quote func", Options{Separator: ";", Template: `{{quote .Text}}`}, `'my file.txt';'it'\''s';/tmp/a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := join(t, tt.opts); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJoin_KeepsInput(t *testing.T) {
+	join(t, Options{Order: OrderChronological})
+	if selected[0].ID != 2 {
+		t.Error("expected the selection to be left unsorted")
+	}
+}
+
+func TestParts(t *testing.T) {
+	j, err := New(Options{Order: OrderChronological, Quote: true})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	parts, err := j.Parts(selected)
+	if err != nil {
+		t.Fatalf("Parts() failed: %v", err)
+	}
+	if want := []string{"/tmp/a", "'my file.txt'", `'it'\''s'`}; !slices.Equal(parts, want) {
+		t.Errorf("Parts() = %q, want %q", parts, want)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, opts := range []Options{
+		{Order: "random"},
+		{Separator: `\x`},
+		{Separator: `a\`},
+		{Template: "{{.Text"},
+	} {
+		if _, err := New(opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestParseSeparator(t *testing.T) {
+	tests := map[string]string{
+		"":       "",
+		" ":      " ",
+		`\n`:     "\n",
+		`\t|\r`:  "\t|\r",
+		`\\n`:    `\n`,
+		"\n":     "\n",
+		`--\0--`: "--\x00--",
+		`, `:     ", ",
+	}
+	for in, want := range tests {
+		got, err := ParseSeparator(in)
+		if err != nil {
+			t.Errorf("ParseSeparator(%q) failed: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSeparator(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"":              "''",
+		"plain":         "plain",
+		"/usr/bin/a-b":  "/usr/bin/a-b",
+		"two words":     "'two words'",
+		"$HOME":         "'$HOME'",
+		"it's":          `'it'\''s'`,
+		"multi\nline":   "'multi\nline'",
+		"key=value,x:y": "key=value,x:y",
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -47,13 +47,14 @@ func ConfigureFromFlags(pflags *pflag.FlagSet) {
 		verboseEnabled = viper.GetBool(config.ViperKeyVerbose)
 	}
 
-	var filePath string
+	var expandedPath string
 	if pflags.Changed(fileConfig) {
-		filePath, _ = pflags.GetString(fileConfig)
+		filePath, _ := pflags.GetString(fileConfig)
+		expandedPath = config.ExpandHomePath(strings.TrimSpace(filePath))
 	} else {
-		filePath = viper.GetString(config.ViperKeyLogFile)
+		// resolved per profile (see config.LogFilePath)
+		expandedPath = config.LogFilePath()
 	}
-	expandedPath := config.ExpandHomePath(strings.TrimSpace(filePath))
 	Configure(verboseEnabled, expandedPath)
 }
 
@@ -0,0 +1,342 @@
+// Package query parses the history filter syntax, e.g. `type:url after:yesterday@12 deploy`.
+//
+// Supported predicates:
+//
+//	after:<when>  before:<when>   capture time; <when> is a relative age (30m, 2h, 3d, 1w),
+//	                              a date (2026-01-01), a date and time (2026-01-01T15:04)
+//	                              or today/yesterday with an optional @HH[:MM]
+//	type:<type>                   content type (text, multi-line, url, email, path, json, code, command,
+//	                              number, color, ip, secret, empty); repeat to allow several
+//	tag:<name>                    tagged items; repeat to require several
+//	len>N len>=N len<N len<=N len=N  length in characters
+//	/regex/ or /regex/i           Go regular expression (i -> case-insensitive)
+//
+// Anything else is free text, used for fuzzy matching in the history window
+// and as case-insensitive substrings by the non-interactive commands.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kaliv0/homie/internal/content"
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// Query is a parsed filter expression.
+type Query struct {
+	After    time.Time
+	Before   time.Time
+	Types    []content.Type
+	Tags     []string
+	MinLen   int
+	LenBelow int
+	Patterns []string
+	// Terms are the free-text words.
+	Terms []string
+}
+
+var lengthPattern = regexp.MustCompile(`^len(>=|<=|>|<|=)(\d+)$`)
+
+// Parse parses the words of a query; now anchors relative times.
+func Parse(args []string, now time.Time) (Query, error) {
+	var q Query
+	for _, tok := range tokenize(strings.Join(args, " ")) {
+		if tok.phrase {
+			q.Terms = append(q.Terms, tok.text)
+			continue
+		}
+		if err := q.add(tok.text, now); err != nil {
+			return Query{}, err
+		}
+	}
+	if !q.After.IsZero() && !q.Before.IsZero() && !q.After.Before(q.Before) {
+		return Query{}, fmt.Errorf("after: (%s) must be earlier than before: (%s)",
+			q.After.Format(time.DateTime), q.Before.Format(time.DateTime))
+	}
+	return q, nil
+}
+
+func (q *Query) add(tok string, now time.Time) error {
+	if pattern, ok := regexToken(tok); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regex %s: %w", tok, err)
+		}
+		q.Patterns = append(q.Patterns, pattern)
+		return nil
+	}
+	if m := lengthPattern.FindStringSubmatch(tok); m != nil {
+		return q.addLength(m[1], m[2])
+	}
+
+	key, value, ok := strings.Cut(tok, ":")
+	if !ok || value == "" {
+		q.Terms = append(q.Terms, tok)
+		return nil
+	}
+	switch key {
+	case "after", "before":
+		t, err := ParseTime(value, now)
+		if err != nil {
+			return fmt.Errorf("invalid %s: value %q: %w", key, value, err)
+		}
+		if key == "after" {
+			q.After = t
+		} else {
+			q.Before = t
+		}
+	case "type":
+		t := content.Type(value)
+		if !slices.Contains(content.Types, t) {
+			return fmt.Errorf("unknown type %q, choose between: %s", value, typeNames())
+		}
+		q.Types = append(q.Types, t)
+	case "tag":
+		if err := storage.ValidateTag(value); err != nil {
+			return fmt.Errorf("invalid tag: %w", err)
+		}
+		q.Tags = append(q.Tags, strings.ToLower(value))
+	default:
+		// not a predicate, e.g. https://example.com
+		q.Terms = append(q.Terms, tok)
+	}
+	return nil
+}
+
+func (q *Query) addLength(op, digits string) error {
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return fmt.Errorf("invalid length %q: %w", digits, err)
+	}
+	switch op {
+	case ">":
+		q.MinLen = max(q.MinLen, n+1)
+	case ">=":
+		q.MinLen = max(q.MinLen, n)
+	case "<":
+		if n == 0 {
+			return errors.New("len<0 matches nothing")
+		}
+		q.LenBelow = minBound(q.LenBelow, n)
+	case "<=":
+		q.LenBelow = minBound(q.LenBelow, n+1)
+	case "=":
+		q.MinLen = max(q.MinLen, n)
+		q.LenBelow = minBound(q.LenBelow, n+1)
+	}
+	return nil
+}
+
+// minBound tightens an exclusive upper bound where 0 means unbounded.
+func minBound(bound, n int) int {
+	if bound == 0 {
+		return n
+	}
+	return min(bound, n)
+}
+
+// Text returns the free-text part of the query.
+func (q Query) Text() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// Filter converts the predicates into a storage filter.
+// With terms set, the free text must occur in the items as well.
+func (q Query) Filter(terms bool) storage.Filter {
+	f := storage.Filter{
+		After:    q.After,
+		Before:   q.Before,
+		MinLen:   q.MinLen,
+		LenBelow: q.LenBelow,
+		Patterns: q.Patterns,
+		Tags:     q.Tags,
+	}
+	for _, t := range q.Types {
+		f.Types = append(f.Types, string(t))
+	}
+	if terms {
+		f.Contains = q.Terms
+	}
+	return f
+}
+
+// regexToken returns the Go pattern of a /regex/ or /regex/i token.
+func regexToken(tok string) (string, bool) {
+	if len(tok) < 3 || tok[0] != '/' {
+		return "", false
+	}
+	switch {
+	case strings.HasSuffix(tok, "/i") && len(tok) > 3:
+		return "(?i)" + tok[1:len(tok)-2], true
+	case strings.HasSuffix(tok, "/"):
+		return tok[1 : len(tok)-1], true
+	default:
+		return "", false
+	}
+}
+
+// ParseTime resolves an after:/before: value, e.g. 2d, 2026-01-01 or yesterday@12.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if d, ok := parseAge(value); ok {
+		return now.Add(-d), nil
+	}
+
+	day, clock, hasClock := strings.Cut(value, "@")
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var base time.Time
+	switch day {
+	case "today":
+		base = midnight
+	case "yesterday":
+		base = midnight.AddDate(0, 0, -1)
+	default:
+		if hasClock {
+			return time.Time{}, errors.New("@HH:MM only follows today or yesterday")
+		}
+		for _, layout := range []string{time.DateOnly, "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+			if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, errors.New("expected an age (30m, 2h, 3d, 1w), a date (2006-01-02[T15:04]), today or yesterday")
+	}
+	if !hasClock {
+		return base, nil
+	}
+	offset, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return base.Add(offset), nil
+}
+
+// ageUnits are the suffixes accepted by relative ages.
+var ageUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseAge parses ages like 30m or 2d.
+func parseAge(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	unit, ok := ageUnits[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// parseClock parses HH or HH:MM into the offset from midnight.
+func parseClock(clock string) (time.Duration, error) {
+	hh, mm, hasMinutes := strings.Cut(clock, ":")
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour %q", hh)
+	}
+	m := 0
+	if hasMinutes {
+		if m, err = strconv.Atoi(mm); err != nil || m < 0 || m > 59 {
+			return 0, fmt.Errorf("invalid minutes %q", mm)
+		}
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func typeNames() string {
+	names := make([]string, 0, len(content.Types))
+	for _, t := range content.Types {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+type token struct {
+	text string
+	// phrase marks "quoted" text, which is never a predicate.
+	phrase bool
+}
+
+// tokenize splits a query on whitespace; "double quotes" keep a phrase together
+// and a /regex/ may contain spaces.
+func tokenize(s string) []token {
+	var (
+		tokens []token
+		b      strings.Builder
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, token{text: b.String()})
+			b.Reset()
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '"' && b.Len() == 0:
+			end := slices.Index(runes[i+1:], '"')
+			if end < 0 {
+				// unbalanced quote -> take the rest literally
+				tokens = append(tokens, token{text: string(runes[i+1:]), phrase: true})
+				i = len(runes)
+				break
+			}
+			tokens = append(tokens, token{text: string(runes[i+1 : i+1+end]), phrase: true})
+			i += end + 1
+		case r == '/' && b.Len() == 0:
+			end := regexEnd(runes, i)
+			if end < 0 {
+				b.WriteRune(r)
+				break
+			}
+			tokens = append(tokens, token{text: string(runes[i:end])})
+			i = end - 1
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// regexEnd returns the end of a /regex/ or /regex/i token starting at start,
+// or -1 when the slashes don't delimit a regex (e.g. the path /etc/hosts).
+func regexEnd(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '/':
+			end := i + 1
+			if end < len(runes) && runes[end] == 'i' {
+				end++
+			}
+			if end == len(runes) || unicode.IsSpace(runes[end]) {
+				if i == start+1 {
+					return -1
+				}
+				return end
+			}
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,168 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+var now = time.Date(2026, 10, 18, 15, 30, 0, 0, time.Local)
+
+func mustParse(t *testing.T, s string) Query {
+	t.Helper()
+	q, err := Parse(strings.Fields(s), now)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", s, err)
+	}
+	return q
+}
+
+func TestParse_Time(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantAfter  time.Time
+		wantBefore time.Time
+	}{
+		{"after:2d", now.Add(-48 * time.Hour), time.Time{}},
+		{"after:90m", now.Add(-90 * time.Minute), time.Time{}},
+		{"after:1w", now.Add(-7 * 24 * time.Hour), time.Time{}},
+		{"before:2026-01-01", time.Time{}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)},
+		{"before:2026-01-01T15:04", time.Time{}, time.Date(2026, 1, 1, 15, 4, 0, 0, time.Local)},
+		{"after:today", time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local), time.Time{}},
+		{"after:yesterday@12 before:yesterday@18:30",
+			time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local), time.Date(2026, 10, 17, 18, 30, 0, 0, time.Local)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			q := mustParse(t, tc.query)
+			if !q.After.Equal(tc.wantAfter) {
+				t.Errorf("after: got %v, want %v", q.After, tc.wantAfter)
+			}
+			if !q.Before.Equal(tc.wantBefore) {
+				t.Errorf("before: got %v, want %v", q.Before, tc.wantBefore)
+			}
+		})
+	}
+}
+
+func TestParse_Predicates(t *testing.T) {
+	q := mustParse(t, "type:url type:json len>500 /deploy-\\d+/i https://example.com notes")
+
+	if want := []content.Type{content.TypeURL, content.TypeJSON}; !slices.Equal(q.Types, want) {
+		t.Errorf("types: got %v, want %v", q.Types, want)
+	}
+	if q.MinLen != 501 || q.LenBelow != 0 {
+		t.Errorf("length: got [%d, %d), want [501, unbounded)", q.MinLen, q.LenBelow)
+	}
+	if want := []string{`(?i)deploy-\d+`}; !slices.Equal(q.Patterns, want) {
+		t.Errorf("patterns: got %q, want %q", q.Patterns, want)
+	}
+	if got, want := q.Text(), "https://example.com notes"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+}
+
+func TestParse_Length(t *testing.T) {
+	tests := []struct {
+		query    string
+		minLen   int
+		lenBelow int
+	}{
+		{"len>10", 11, 0},
+		{"len>=10", 10, 0},
+		{"len<10", 0, 10},
+		{"len<=10", 0, 11},
+		{"len=10", 10, 11},
+		{"len=0", 0, 1},
+		{"len>5 len<20 len<8", 6, 8},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			q := mustParse(t, tc.query)
+			if q.MinLen != tc.minLen || q.LenBelow != tc.lenBelow {
+				t.Errorf("got [%d, %d), want [%d, %d)", q.MinLen, q.LenBelow, tc.minLen, tc.lenBelow)
+			}
+		})
+	}
+}
+
+func TestParse_Tokens(t *testing.T) {
+	tests := []struct {
+		query    string
+		patterns []string
+		terms    []string
+	}{
+		{`/foo bar/ baz`, []string{"foo bar"}, []string{"baz"}},
+		{`/etc/hosts`, nil, []string{"/etc/hosts"}},
+		{`/a\/b/`, []string{`a\/b`}, nil},
+		{`"type:url is text" x`, nil, []string{"type:url is text", "x"}},
+		{`"unbalanced quote`, nil, []string{"unbalanced quote"}},
+		{`key: value`, nil, []string{"key:", "value"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := Parse([]string{tc.query}, now)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if !slices.Equal(q.Patterns, tc.patterns) {
+				t.Errorf("patterns: got %q, want %q", q.Patterns, tc.patterns)
+			}
+			if !slices.Equal(q.Terms, tc.terms) {
+				t.Errorf("terms: got %q, want %q", q.Terms, tc.terms)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		"after:soon",
+		"before:2026-13-01",
+		"after:2026-01-01@12",
+		"after:today@25",
+		"type:image",
+		"len<0",
+		"/(unclosed/",
+		"after:1d before:2d",
+	}
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if _, err := Parse(strings.Fields(query), now); err == nil {
+				t.Errorf("expected error for %q, got nil", query)
+			}
+		})
+	}
+}
+
+func TestParse_Tags(t *testing.T) {
+	q := mustParse(t, "tag:Prod tag:ticket-123 deploy")
+	if !slices.Equal(q.Tags, []string{"prod", "ticket-123"}) || q.Text() != "deploy" {
+		t.Errorf("unexpected query %+v", q)
+	}
+	if got := q.Filter(false).Tags; !slices.Equal(got, []string{"prod", "ticket-123"}) {
+		t.Errorf("expected tags in the filter, got %q", got)
+	}
+	if _, err := Parse([]string{"tag:a,b"}, now); err == nil {
+		t.Error("expected error for a tag with a comma")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	q := mustParse(t, "type:url after:1h len<100 /x/ deploy")
+
+	f := q.Filter(false)
+	if !f.After.Equal(now.Add(-time.Hour)) || !slices.Equal(f.Types, []string{"url"}) ||
+		f.LenBelow != 100 || !slices.Equal(f.Patterns, []string{"x"}) {
+		t.Errorf("unexpected filter %+v", f)
+	}
+	if len(f.Contains) != 0 {
+		t.Errorf("expected no substring terms, got %q", f.Contains)
+	}
+	if got := q.Filter(true).Contains; !slices.Equal(got, []string{"deploy"}) {
+		t.Errorf("expected terms as substrings, got %q", got)
+	}
+}
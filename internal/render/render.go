@@ -0,0 +1,183 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// Format selects how clipboard items are printed.
+type Format string
+
+const (
+	FormatPlain    Format = "plain"
+	FormatJSON     Format = "json"
+	FormatNDJSON   Format = "ndjson"
+	FormatTSV      Format = "tsv"
+	FormatTemplate Format = "template"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatPlain, FormatJSON, FormatNDJSON, FormatTSV, FormatTemplate}
+
+// Options configures the output.
+type Options struct {
+	Format Format
+	// Template is the text/template executed per item with FormatTemplate.
+	Template string
+	// NullSep ends records with NUL instead of a newline (for xargs -0, fzf --read0).
+	NullSep bool
+}
+
+// Record is the exported view of a clipboard item, used by the json formats and templates.
+type Record struct {
+	ID     int       `json:"id"`
+	Text   string    `json:"text"`
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Copies int       `json:"copies"`
+	Pinned bool      `json:"pinned"`
+	Tags   []string  `json:"tags,omitempty"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// NewRecord converts a stored item.
+func NewRecord(item storage.ClipboardItem) Record {
+	return Record{
+		ID:     item.ID,
+		Text:   item.ClipText,
+		Time:   item.TimeStamp,
+		Type:   string(item.Type()),
+		Copies: max(item.CopyCount, 1),
+		Pinned: item.Pinned,
+		Tags:   item.Tags,
+		Note:   item.Note,
+	}
+}
+
+// TemplateFuncs are the functions available to item templates.
+var TemplateFuncs = template.FuncMap{
+	"oneline": EscapeField,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Writer prints clipboard items in the configured format.
+type Writer struct {
+	out   *bufio.Writer
+	opts  Options
+	tmpl  *template.Template
+	sep   byte
+	count int
+}
+
+// NewWriter validates opts and returns a writer on w.
+func NewWriter(w io.Writer, opts Options) (*Writer, error) {
+	if !slices.Contains(Formats, opts.Format) {
+		return nil, fmt.Errorf("unknown format %q, choose between: %s", opts.Format, formatNames())
+	}
+	if opts.NullSep && opts.Format == FormatJSON {
+		return nil, fmt.Errorf("NUL separated output doesn't apply to the %s format", FormatJSON)
+	}
+
+	wr := &Writer{out: bufio.NewWriter(w), opts: opts, sep: '\n'}
+	if opts.NullSep {
+		wr.sep = 0
+	}
+	if opts.Format == FormatTemplate {
+		if opts.Template == "" {
+			return nil, fmt.Errorf("the %s format needs a template", FormatTemplate)
+		}
+		tmpl, err := template.New("item").Funcs(TemplateFuncs).Parse(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		wr.tmpl = tmpl
+	}
+	return wr, nil
+}
+
+// Write prints items; call Close to finish the output.
+func (w *Writer) Write(items []storage.ClipboardItem) error {
+	for _, item := range items {
+		if err := w.write(NewRecord(item)); err != nil {
+			return err
+		}
+		w.count++
+	}
+	return nil
+}
+
+func (w *Writer) write(r Record) error {
+	switch w.opts.Format {
+	case FormatJSON:
+		prefix := ",\n  "
+		if w.count == 0 {
+			prefix = "[\n  "
+		}
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode clipboard item (id=%d): %w", r.ID, err)
+		}
+		_, _ = w.out.WriteString(prefix)
+		_, _ = w.out.Write(b)
+		return nil
+	case FormatNDJSON:
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode clipboard item (id=%d): %w", r.ID, err)
+		}
+		_, _ = w.out.Write(b)
+	case FormatTSV:
+		_, _ = w.out.WriteString(strings.Join([]string{
+			strconv.Itoa(r.ID), r.Time.Format(time.RFC3339), r.Type, EscapeField(r.Text),
+		}, "\t"))
+	case FormatTemplate:
+		if err := w.tmpl.Execute(w.out, r); err != nil {
+			return fmt.Errorf("failed to render clipboard item (id=%d): %w", r.ID, err)
+		}
+	default:
+		_, _ = w.out.WriteString(r.Text)
+	}
+	return w.out.WriteByte(w.sep)
+}
+
+// Close terminates the output (closing the json array) and flushes it.
+func (w *Writer) Close() error {
+	if w.opts.Format == FormatJSON {
+		if w.count == 0 {
+			_, _ = w.out.WriteString("[")
+		}
+		_, _ = w.out.WriteString("\n]\n")
+	}
+	if err := w.out.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// fieldEscaper keeps a text on one line: backslash, tab, newline and carriage return are escaped.
+var fieldEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// EscapeField keeps s on one line, as in the tsv format and the oneline template function.
+func EscapeField(s string) string {
+	return fieldEscaper.Replace(s)
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+var ts = time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+var items = []storage.ClipboardItem{
+	{ID: 2, ClipText: "https://example.com", TimeStamp: ts, CopyCount: 3, Pinned: true, Tags: []string{"prod"}, Note: "dashboard"},
+	{ID: 1, ClipText: "line one\n\tline two", TimeStamp: ts},
+}
+
+func render(t *testing.T, opts Options, items []storage.ClipboardItem) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, opts)
+	if err != nil {
+		t.Fatalf("NewWriter(%+v) failed: %v", opts, err)
+	}
+	if err := w.Write(items); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriter_Formats(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"plain", Options{Format: FormatPlain}, "https://example.com\nline one\n\tline two\n"},
+		{"plain nul", Options{Format: FormatPlain, NullSep: true}, "https://example.com\x00line one\n\tline two\x00"},
+		{"tsv", Options{Format: FormatTSV},
+			"2\t2026-10-18T09:30:00Z\turl\thttps://example.com\n1\t2026-10-18T09:30:00Z\tmulti-line\tline one\\n\\tline two\n"},
+		{"template", Options{Format: FormatTemplate, Template: "{{.ID}}:{{oneline .Text}}"},
+			"2:https://example.com\n1:line one\\n\\tline two\n"},
+		{"template json func", Options{Format: FormatTemplate, Template: "{{json .Text}}", NullSep: true},
+			`"https://example.com"` + "\x00" + `"line one\n\tline two"` + "\x00"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := render(t, tc.opts, items); got != tc.want {
+				t.Errorf("got  %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriter_JSON(t *testing.T) {
+	out := render(t, Options{Format: FormatJSON}, items)
+
+	var records []Record
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("invalid json %q: %v", out, err)
+	}
+	want := Record{ID: 2, Text: "https://example.com", Time: ts, Type: "url", Copies: 3, Pinned: true,
+		Tags: []string{"prod"}, Note: "dashboard"}
+	if len(records) != 2 || !reflect.DeepEqual(records[0], want) {
+		t.Errorf("unexpected records %+v", records)
+	}
+	if records[1].Copies != 1 {
+		t.Errorf("expected copies to default to 1, got %d", records[1].Copies)
+	}
+}
+
+func TestWriter_JSONEmpty(t *testing.T) {
+	out := render(t, Options{Format: FormatJSON}, nil)
+
+	var records []Record
+	if err := json.Unmarshal([]byte(out), &records); err != nil || len(records) != 0 {
+		t.Fatalf("expected empty json array, got %q (%v)", out, err)
+	}
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	out := render(t, Options{Format: FormatNDJSON}, items)
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out)
+	}
+	for _, line := range lines {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Errorf("invalid json line %q: %v", line, err)
+		}
+	}
+}
+
+func TestNewWriter_Errors(t *testing.T) {
+	tests := []Options{
+		{Format: "xml"},
+		{Format: FormatJSON, NullSep: true},
+		{Format: FormatTemplate},
+		{Format: FormatTemplate, Template: "{{.Nope"},
+	}
+	for _, opts := range tests {
+		if _, err := NewWriter(&bytes.Buffer{}, opts); err == nil {
+			t.Errorf("expected error for %+v, got nil", opts)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Expander fills in the placeholders of snippets:
+//
+//	{{date}} {{date "15:04"}}         current date (2006-01-02) or time in a Go layout
+//	{{clipboard}}                     current clipboard content
+//	{{env "USER"}}                    environment variable
+//	{{prompt "host"}} {{prompt "host" "localhost"}}  asks for a value (once per name), with an optional default
+//
+// Any other template syntax is rejected; {{"{{"}} writes literal braces.
+type Expander struct {
+	Now       func() time.Time
+	Clipboard func() (string, error)
+	// Prompt asks for the value of name; def is the default offered (may be empty).
+	Prompt func(name, def string) (string, error)
+	Getenv func(string) string
+}
+
+// NewExpander returns an Expander using the system clock and environment.
+func NewExpander(clipboard func() (string, error), prompt func(name, def string) (string, error)) *Expander {
+	return &Expander{Now: time.Now, Clipboard: clipboard, Prompt: prompt, Getenv: os.Getenv}
+}
+
+// Check parses text without expanding it. Template syntax other than the placeholders,
+// e.g. the {{ .Values.image }} of a Helm chart, is rejected rather than expanded to "<no value>".
+func Check(text string) error {
+	_, err := parseSnippet(text, (&Expander{}).funcs(nil))
+	return err
+}
+
+// Expand fills in the placeholders of text. The clipboard is read at most once, every prompt asked at most once.
+func (e *Expander) Expand(text string) (string, error) {
+	answers := make(map[string]string)
+	tmpl, err := parseSnippet(text, e.funcs(answers))
+	if err != nil {
+		return "", fmt.Errorf("invalid snippet template: %w", err)
+	}
+	var b strings.Builder
+	if err = tmpl.Execute(&b, nil); err != nil {
+		return "", fmt.Errorf("failed to expand snippet: %w", err)
+	}
+	return b.String(), nil
+}
+
+// parseSnippet parses text and checks that its actions are placeholders or quoted strings.
+func parseSnippet(text string, funcs template.FuncMap) (*template.Template, error) {
+	tmpl, err := template.New("snippet").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Name() != tmpl.Name() {
+			return nil, fmt.Errorf("template %q: defining templates is not supported in snippets", t.Name())
+		}
+	}
+	if tmpl.Tree == nil {
+		return tmpl, nil
+	}
+	for _, n := range tmpl.Tree.Root.Nodes {
+		if isPlaceholder(n, funcs) {
+			continue
+		}
+		location, context := tmpl.Tree.ErrorContext(n)
+		return nil, fmt.Errorf("%s: unsupported template syntax %s: use {{date}}, {{clipboard}}, {{env}} or {{prompt}}, "+
+			`and {{"{{"}} for literal braces`, location, context)
+	}
+	return tmpl, nil
+}
+
+// isPlaceholder reports whether n is plain text, a comment, a quoted string such as {{"{{"}}
+// or a call of one of funcs with quoted arguments.
+func isPlaceholder(n parse.Node, funcs template.FuncMap) bool {
+	switch n := n.(type) {
+	case *parse.TextNode, *parse.CommentNode:
+		return true
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 {
+			return false
+		}
+		args := n.Pipe.Cmds[0].Args
+		if _, ok := args[0].(*parse.StringNode); ok && len(args) == 1 {
+			return true
+		}
+		if id, ok := args[0].(*parse.IdentifierNode); !ok || funcs[id.Ident] == nil {
+			return false
+		}
+		for _, arg := range args[1:] {
+			if _, ok := arg.(*parse.StringNode); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (e *Expander) funcs(answers map[string]string) template.FuncMap {
+	var (
+		clip     string
+		clipRead bool
+	)
+	return template.FuncMap{
+		"date": func(layout ...string) (string, error) {
+			if len(layout) > 1 {
+				return "", fmt.Errorf("date takes at most one layout, got %d", len(layout))
+			}
+			if len(layout) == 0 {
+				return e.Now().Format(time.DateOnly), nil
+			}
+			return e.Now().Format(layout[0]), nil
+		},
+		"clipboard": func() (string, error) {
+			if !clipRead {
+				text, err := e.Clipboard()
+				if err != nil {
+					return "", err
+				}
+				clip, clipRead = text, true
+			}
+			return clip, nil
+		},
+		"env": func(name string) string {
+			return e.Getenv(name)
+		},
+		"prompt": func(name string, def ...string) (string, error) {
+			if answer, ok := answers[name]; ok {
+				return answer, nil
+			}
+			if len(def) > 1 {
+				return "", fmt.Errorf("prompt %q takes at most one default, got %d", name, len(def))
+			}
+			answer, err := e.Prompt(name, strings.Join(def, ""))
+			if err != nil {
+				return "", err
+			}
+			answers[name] = answer
+			return answer, nil
+		},
+	}
+}
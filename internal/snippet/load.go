@@ -0,0 +1,148 @@
+// Package snippet loads snippet files and expands the placeholders of snippets.
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/kaliv0/homie/internal/storage"
+)
+
+// Extensions are the snippet file types by extension.
+var Extensions = []string{".yaml", ".yml", ".toml"}
+
+// IsSnippetFile reports whether path has a snippet file extension.
+func IsSnippetFile(path string) bool {
+	return slices.Contains(Extensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// Load reads the snippet files directly in dir, in name order. A file maps snippet names either to the text
+// or to a table with text and tags:
+//
+//	greeting: Hello {{prompt "name"}}!
+//	deploy:
+//	  tags: [ops]
+//	  text: make deploy ENV={{prompt "env" "staging"}}
+//
+// A missing dir holds no snippets. Broken files are reported in the error while the snippets
+// of the other files are still returned.
+func Load(dir string) ([]storage.Snippet, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet directory %q: %w", dir, err)
+	}
+
+	var (
+		snippets []storage.Snippet
+		errs     []error
+		seen     = make(map[string]string)
+	)
+	for _, e := range entries {
+		if e.IsDir() || !IsSnippetFile(e.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		loaded, err := loadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, s := range loaded {
+			if prev, ok := seen[s.Name]; ok {
+				errs = append(errs, fmt.Errorf("%s: snippet %q is already defined in %s", path, s.Name, prev))
+				continue
+			}
+			seen[s.Name] = path
+			snippets = append(snippets, s)
+		}
+	}
+	return snippets, errors.Join(errs...)
+}
+
+// fileSnippet is the table form of a snippet in a file.
+type fileSnippet struct {
+	Text string
+	Tags []string
+}
+
+func loadFile(path string) ([]storage.Snippet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet file: %w", err)
+	}
+	var raw map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snippet file %q: %w", path, err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	snippets := make([]storage.Snippet, 0, len(names))
+	for _, name := range names {
+		fs, err := decodeSnippet(raw[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: snippet %q: %w", path, name, err)
+		}
+		if err = storage.ValidateSnippetName(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err = Check(fs.Text); err != nil {
+			return nil, fmt.Errorf("%s: snippet %q: invalid placeholders: %w", path, name, err)
+		}
+		snippets = append(snippets, storage.Snippet{Name: name, Text: fs.Text, Tags: fs.Tags, Source: path})
+	}
+	return snippets, nil
+}
+
+func decodeSnippet(v any) (fileSnippet, error) {
+	switch v := v.(type) {
+	case string:
+		return fileSnippet{Text: v}, nil
+	case map[string]any:
+		var fs fileSnippet
+		for key, value := range v {
+			switch key {
+			case "text":
+				text, ok := value.(string)
+				if !ok {
+					return fileSnippet{}, errors.New("text must be a string")
+				}
+				fs.Text = text
+			case "tags":
+				tags, ok := value.([]any)
+				if !ok {
+					return fileSnippet{}, errors.New("tags must be a list")
+				}
+				for _, t := range tags {
+					fs.Tags = append(fs.Tags, fmt.Sprint(t))
+				}
+			default:
+				return fileSnippet{}, fmt.Errorf("unknown key %q (use text and tags)", key)
+			}
+		}
+		if fs.Text == "" {
+			return fileSnippet{}, errors.New("text is missing")
+		}
+		return fs, nil
+	default:
+		return fileSnippet{}, errors.New("expected a text or a table with text and tags")
+	}
+}
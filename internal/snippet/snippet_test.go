@@ -0,0 +1,196 @@
+package snippet
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ops.yaml"), `
+deploy:
+  tags: [ops, k8s]
+  text: |
+    kubectl rollout restart deploy/{{prompt "app"}}
+greeting: Hello {{env "USER"}}
+`)
+	writeFile(t, filepath.Join(dir, "mail.toml"), `
+signature = "Regards"
+
+[reply]
+text = "Hi {{prompt \"name\"}},"
+tags = ["mail"]
+`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	snippets, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	var got []string
+	for _, s := range snippets {
+		got = append(got, s.Name+"@"+filepath.Base(s.Source)+":"+strings.Join(s.Tags, "+"))
+	}
+	want := "reply@mail.toml:mail signature@mail.toml: deploy@ops.yaml:ops+k8s greeting@ops.yaml:"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Load() = %v, want %s", got, want)
+	}
+	if snippets[2].Text != "kubectl rollout restart deploy/{{prompt \"app\"}}\n" {
+		t.Errorf("unexpected text %q", snippets[2].Text)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "ok: fine\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "ok: duplicate\n")
+	writeFile(t, filepath.Join(dir, "c.yml"), "bad:\n  text: x\n  colour: red\n")
+	writeFile(t, filepath.Join(dir, "d.toml"), "not toml")
+
+	snippets, err := Load(dir)
+	if len(snippets) != 1 || snippets[0].Text != "fine" {
+		t.Errorf("expected the valid snippet to load, got %+v", snippets)
+	}
+	for _, want := range []string{"already defined", `unknown key "colour"`, "d.toml"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error mentioning %q, got %v", want, err)
+		}
+	}
+
+	if snippets, err = Load(filepath.Join(dir, "missing")); err != nil || snippets != nil {
+		t.Errorf("expected no snippets for a missing dir, got %v, %v", snippets, err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	var prompts, clipReads int
+	e := &Expander{
+		Now: func() time.Time { return time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC) },
+		Clipboard: func() (string, error) {
+			clipReads++
+			return "HOM-42", nil
+		},
+		Prompt: func(name, def string) (string, error) {
+			prompts++
+			if def != "" {
+				return def, nil
+			}
+			return "<" + name + ">", nil
+		},
+		Getenv: func(name string) string { return "env-" + name },
+	}
+
+	got, err := e.Expand(`{{date}} {{date "15:04"}} {{clipboard}}/{{clipboard}} {{env "USER"}} ` +
+		`ssh {{prompt "host"}} -p {{prompt "port" "22"}} # {{prompt "host"}}`)
+	if err != nil {
+		t.Fatalf("Expand() failed: %v", err)
+	}
+	want := "2026-10-18 09:30 HOM-42/HOM-42 env-USER ssh <host> -p 22 # <host>"
+	if got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+	if clipReads != 1 || prompts != 2 {
+		t.Errorf("expected one clipboard read and two prompts, got %d and %d", clipReads, prompts)
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	e := &Expander{Prompt: func(string, string) (string, error) { return "", errors.New("cancelled") }}
+	for _, text := range []string{`{{prompt "host"}}`, `{{unknown}}`, `{{date "a" "b"}}`} {
+		if _, err := e.Expand(text); err == nil {
+			t.Errorf("expected Expand(%q) to fail", text)
+		}
+	}
+	if err := Check(`{{prompt "x"}} {{date}}`); err != nil {
+		t.Errorf("Check() failed: %v", err)
+	}
+	if err := Check(`{{prompt "x"`); err == nil {
+		t.Error("expected Check() to reject a broken template")
+	}
+}
+
+func TestCheck_ForeignTemplates(t *testing.T) {
+	for _, text := range []string{
+		`image: {{ .Values.image }}`,
+		`run: echo ${{ github.sha }}`,
+		`{{ $host := prompt "host" }}{{ $host }}`,
+		`{{ prompt "host" | printf "%s" }}`,
+		`{{ env (prompt "name") }}`,
+		`{{ if env "CI" }}ci{{ end }}`,
+		`{{ define "x" }}x{{ end }}`,
+		`{{ template "x" }}`,
+	} {
+		if err := Check(text); err == nil {
+			t.Errorf("expected Check(%q) to reject the template syntax", text)
+		}
+	}
+	err := Check(`image: {{ .Values.image }}`)
+	if err == nil || !strings.Contains(err.Error(), `{{.Values.image}}`) || !strings.Contains(err.Error(), "literal braces") {
+		t.Errorf("expected the error to name the action and the escape, got %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "helm.yaml"), "values: 'image: {{ .Values.image }}'\n")
+	if snippets, err := Load(dir); err == nil || len(snippets) != 0 {
+		t.Errorf("expected Load() to reject the Helm values, got %+v, %v", snippets, err)
+	}
+}
+
+func TestExpand_LiteralBraces(t *testing.T) {
+	e := &Expander{Getenv: func(string) string { return "web" }}
+	text := `image: {{"{{"}} .Values.image }} # {{/* set by helm */}}{{env "APP"}}`
+	if err := Check(text); err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	got, err := e.Expand(text)
+	if err != nil {
+		t.Fatalf("Expand() failed: %v", err)
+	}
+	if want := "image: {{ .Values.image }} # web"; got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, dir, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	// give the watcher time to register the directory
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+	writeFile(t, filepath.Join(dir, "ops.yaml"), "a: b\n")
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change after writing a snippet file")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() failed: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package snippet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce collects the burst of events editors cause on save into one change.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch calls onChange after snippet files in dir were created, written, renamed or removed,
+// until ctx is done. dir has to exist.
+func Watch(ctx context.Context, dir string, onChange func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create snippet watcher: %w", err)
+	}
+	defer func() {
+		_ = w.Close()
+	}()
+	if err = w.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch snippet directory %q: %w", dir, err)
+	}
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if IsSnippetFile(ev.Name) && !ev.Has(fsnotify.Chmod) {
+				timer.Reset(watchDebounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("snippet watcher failed: %w", err)
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
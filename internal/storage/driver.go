@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"database/sql"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+// driverName is the sqlite3 driver extended with the SQL functions used by Filter.
+const driverName = "sqlite3_homie"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// regexp(pattern, text) backs the "text REGEXP pattern" operator
+			if err := conn.RegisterFunc("regexp", matchRegexp, true); err != nil {
+				return err
+			}
+			// classify(text) backfills the content_type column
+			return conn.RegisterFunc("classify", classify, true)
+		},
+	})
+}
+
+// regexps caches compiled patterns, REGEXP is evaluated once per row.
+var regexps sync.Map
+
+func matchRegexp(pattern, text string) (bool, error) {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(text), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	regexps.Store(pattern, re)
+	return re.MatchString(text), nil
+}
+
+func classify(text string) string {
+	return string(content.Classify(text))
+}
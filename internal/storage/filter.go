@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Filter narrows clipboard items down in SQL; the zero value matches everything.
+type Filter struct {
+	// After and Before bound the capture time (zero -> unbounded).
+	After  time.Time
+	Before time.Time
+	// Types keeps items of any of the given content types.
+	Types []string
+	// Tags keeps items tagged with all of the given tags.
+	Tags []string
+	// MinLen <= length < LenBelow in characters (LenBelow 0 -> unbounded).
+	MinLen   int
+	LenBelow int
+	// Patterns are Go regular expressions that must all match.
+	Patterns []string
+	// Contains are substrings that must all occur (case-insensitive).
+	Contains []string
+}
+
+// Cursor is a position in history, which is ordered by (time_stamp, id) descending.
+// Unlike an offset it stays valid while items are added, bumped or deleted.
+// The zero value is the start of history.
+type Cursor struct {
+	TimeStamp time.Time
+	ID        int
+}
+
+// CursorAfter returns the position right after item.
+func CursorAfter(item ClipboardItem) Cursor {
+	return Cursor{TimeStamp: item.TimeStamp, ID: item.ID}
+}
+
+// IsZero reports whether c is the start of history.
+func (c Cursor) IsZero() bool {
+	return c.TimeStamp.IsZero() && c.ID == 0
+}
+
+// where builds the WHERE clause (empty for no conditions) and its arguments.
+func (f Filter) where() (string, []any) {
+	conds, args := f.conditions()
+	return joinConditions(conds), args
+}
+
+func joinConditions(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+func (f Filter) conditions() ([]string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if !f.After.IsZero() {
+		conds = append(conds, "time_stamp > ?")
+		args = append(args, f.After.Local())
+	}
+	if !f.Before.IsZero() {
+		conds = append(conds, "time_stamp < ?")
+		args = append(args, f.Before.Local())
+	}
+	if len(f.Types) > 0 {
+		conds = append(conds, "content_type IN (?"+strings.Repeat(", ?", len(f.Types)-1)+")")
+		for _, t := range f.Types {
+			args = append(args, t)
+		}
+	}
+	for _, t := range f.Tags {
+		conds = append(conds, "EXISTS (SELECT 1 FROM item_tags WHERE item_id = clipboard_items.id AND tag = ?)")
+		args = append(args, strings.ToLower(t))
+	}
+	if f.MinLen > 0 {
+		conds = append(conds, "length(clip_text) >= ?")
+		args = append(args, f.MinLen)
+	}
+	if f.LenBelow > 0 {
+		conds = append(conds, "length(clip_text) < ?")
+		args = append(args, f.LenBelow)
+	}
+	for _, p := range f.Patterns {
+		conds = append(conds, "clip_text REGEXP ?")
+		args = append(args, p)
+	}
+	for _, c := range f.Contains {
+		conds = append(conds, "instr(lower(clip_text), lower(?)) > 0")
+		args = append(args, c)
+	}
+	return conds, args
+}
+
+// Search returns the clipboard items matching f ordered by timestamp descending.
+func (r *Repository) Search(f Filter, offset, limit int) ([]ClipboardItem, error) {
+	where, args := f.where()
+	var items []ClipboardItem
+	err := r.db.Select(&items, `
+		SELECT `+itemColumns+` 
+		FROM clipboard_items 
+		`+where+` 
+		ORDER BY time_stamp DESC, id DESC 
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard items (offset=%d, limit=%d): %w", offset, limit, err)
+	}
+	return items, nil
+}
+
+// SearchAfter returns up to limit clipboard items matching f that come after cursor (keyset pagination).
+// The (time_stamp, id) comparison is served by idx_time_stamp, whose entries end with the rowid.
+func (r *Repository) SearchAfter(f Filter, cursor Cursor, limit int) ([]ClipboardItem, error) {
+	conds, args := f.conditions()
+	if !cursor.IsZero() {
+		conds = append(conds, "(time_stamp, id) < (?, ?)")
+		args = append(args, cursor.TimeStamp, cursor.ID)
+	}
+	var items []ClipboardItem
+	err := r.db.Select(&items, `
+		SELECT `+itemColumns+` 
+		FROM clipboard_items 
+		`+joinConditions(conds)+` 
+		ORDER BY time_stamp DESC, id DESC 
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard items (after id=%d, limit=%d): %w", cursor.ID, limit, err)
+	}
+	return items, nil
+}
+
+// CountMatching returns the number of records matching f.
+func (r *Repository) CountMatching(f Filter) (int, error) {
+	where, args := f.where()
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM clipboard_items `+where, args...); err != nil {
+		return 0, fmt.Errorf("failed to count clipboard items: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteMatching removes the records matching f and returns how many were deleted.
+func (r *Repository) DeleteMatching(f Filter) (int64, error) {
+	where, args := f.where()
+	res, err := r.db.Exec(`DELETE FROM clipboard_items `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete clipboard items: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted clipboard items: %w", err)
+	}
+	return n, nil
+}
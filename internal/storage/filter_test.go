@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaliv0/homie/internal/content"
+)
+
+// insertItemAt inserts a clipboard item captured at ts.
+func insertItemAt(t *testing.T, repo *Repository, text string, ts time.Time) {
+	t.Helper()
+	_, err := repo.db.Exec(`
+		INSERT INTO clipboard_items (clip_text, text_hash, time_stamp, content_type)
+		VALUES (?, ?, ?, ?)
+	`, text, "hash-"+text, ts, content.Classify(text))
+	if err != nil {
+		t.Fatalf("insertItemAt(%q) failed: %v", text, err)
+	}
+}
+
+func texts(items []ClipboardItem) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.ClipText)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	repo := setupTestDB(t)
+	now := time.Now()
+	insertItemAt(t, repo, "https://example.com/old", now.Add(-72*time.Hour))
+	insertItemAt(t, repo, "https://example.com/new", now.Add(-2*time.Hour))
+	insertItemAt(t, repo, `{"deploy": true}`, now.Add(-time.Hour))
+	insertItemAt(t, repo, "Deploy notes\nline two", now.Add(-time.Minute))
+	insertItemAt(t, repo, "", now)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"zero filter", Filter{}, []string{"", "Deploy notes\nline two", `{"deploy": true}`,
+			"https://example.com/new", "https://example.com/old"}},
+		{"after", Filter{After: now.Add(-90 * time.Minute)}, []string{"", "Deploy notes\nline two", `{"deploy": true}`}},
+		{"before", Filter{Before: now.Add(-24 * time.Hour)}, []string{"https://example.com/old"}},
+		{"type", Filter{Types: []string{"url"}}, []string{"https://example.com/new", "https://example.com/old"}},
+		{"types", Filter{Types: []string{"json", "multi-line"}}, []string{"Deploy notes\nline two", `{"deploy": true}`}},
+		{"type and time", Filter{Types: []string{"url"}, After: now.Add(-24 * time.Hour)},
+			[]string{"https://example.com/new"}},
+		{"min length", Filter{MinLen: 20}, []string{"Deploy notes\nline two", "https://example.com/new",
+			"https://example.com/old"}},
+		{"length below", Filter{LenBelow: 1}, []string{""}},
+		{"regex", Filter{Patterns: []string{`/(old|new)$`}}, []string{"https://example.com/new", "https://example.com/old"}},
+		{"case-insensitive regex", Filter{Patterns: []string{`(?i)^deploy`}}, []string{"Deploy notes\nline two"}},
+		{"contains ignores case", Filter{Contains: []string{"DEPLOY"}}, []string{"Deploy notes\nline two", `{"deploy": true}`}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := repo.Search(tc.filter, 0, 10)
+			if err != nil {
+				t.Fatalf("Search() failed: %v", err)
+			}
+			if got := texts(items); !slices.Equal(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			count, err := repo.CountMatching(tc.filter)
+			if err != nil {
+				t.Fatalf("CountMatching() failed: %v", err)
+			}
+			if count != len(tc.want) {
+				t.Errorf("expected count %d, got %d", len(tc.want), count)
+			}
+		})
+	}
+}
+
+func TestSearch_InvalidRegex(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 1)
+
+	_, err := repo.Search(Filter{Patterns: []string{"("}}, 0, 10)
+	if err == nil || !strings.Contains(err.Error(), "missing closing )") {
+		t.Fatalf("expected regexp error, got %v", err)
+	}
+}
+
+func TestDeleteMatching(t *testing.T) {
+	repo := setupTestDB(t)
+	now := time.Now()
+	insertItemAt(t, repo, "https://a.example", now)
+	insertItemAt(t, repo, "plain", now)
+	insertItemAt(t, repo, "https://b.example", now)
+
+	n, err := repo.DeleteMatching(Filter{Types: []string{"url"}})
+	if err != nil {
+		t.Fatalf("DeleteMatching() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 deleted items, got %d", n)
+	}
+	if got := texts(mustRead(t, repo, 0, 10)); !slices.Equal(got, []string{"plain"}) {
+		t.Errorf("expected only %q to remain, got %q", "plain", got)
+	}
+}
+
+// readAllAfter pages through history with cursors of the given size.
+func readAllAfter(t *testing.T, repo *Repository, f Filter, size int, between func()) []ClipboardItem {
+	t.Helper()
+	var (
+		all    []ClipboardItem
+		cursor Cursor
+	)
+	for {
+		page, err := repo.SearchAfter(f, cursor, size)
+		if err != nil {
+			t.Fatalf("SearchAfter() failed: %v", err)
+		}
+		all = append(all, page...)
+		if len(page) < size {
+			return all
+		}
+		cursor = CursorAfter(page[len(page)-1])
+		if between != nil {
+			between()
+		}
+	}
+}
+
+func TestSearchAfter_PagesThroughHistory(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 7)
+
+	got := texts(readAllAfter(t, repo, Filter{}, 3, nil))
+	want := texts(mustRead(t, repo, 0, 10))
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSearchAfter_SameTimestampOrderedByID(t *testing.T) {
+	repo := setupTestDB(t)
+	ts := time.Now()
+	for _, text := range []string{"a", "b", "c", "d"} {
+		insertItemAt(t, repo, text, ts)
+	}
+
+	if got, want := texts(readAllAfter(t, repo, Filter{}, 1, nil)), []string{"d", "c", "b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSearchAfter_StableWhileWriting(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 6)
+
+	// the daemon adds new items and bumps an unread one between pages
+	writes := 0
+	got := readAllAfter(t, repo, Filter{}, 2, func() {
+		writes++
+		if err := repo.Write(fmt.Appendf(nil, "new-%d", writes)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if writes == 1 {
+			if _, err := repo.db.Exec(`UPDATE clipboard_items SET time_stamp = ? WHERE clip_text = 'item-0'`,
+				time.Now()); err != nil {
+				t.Fatalf("bump failed: %v", err)
+			}
+		}
+	})
+
+	want := []string{"item-5", "item-4", "item-3", "item-2", "item-1"}
+	if !slices.Equal(texts(got), want) {
+		t.Errorf("expected %v without duplicates, got %v", want, texts(got))
+	}
+}
+
+func TestSearchAfter_Filter(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 12)
+
+	got := texts(readAllAfter(t, repo, Filter{Contains: []string{"item-1"}}, 2, nil))
+	if want := []string{"item-11", "item-10", "item-1"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// QueueEntry is a text waiting in the paste queue; Pos orders the queue.
+type QueueEntry struct {
+	Pos      int       `db:"pos"`
+	Text     string    `db:"clip_text"`
+	QueuedAt time.Time `db:"queued_at"`
+}
+
+func (r *Repository) migrateQueue() error {
+	return queueTable.migrate(r.db)
+}
+
+// ReplaceQueue replaces the paste queue with texts, to be pasted in the given order.
+// The texts are copies, so the queue outlives the clean-up of the items they came from.
+func (r *Repository) ReplaceQueue(texts []string) (err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin paste queue update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = queueTable.clear(tx); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, text := range texts {
+		if err = queueTable.add(tx, text, now); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit paste queue: %w", err)
+	}
+	return nil
+}
+
+// PopQueue takes the first entry off the paste queue and returns it; ErrNotFound when it is empty.
+// The entry is claimed at once, so two pastes never get the same one; RestoreQueueEntry puts it back
+// when the paste fails.
+func (r *Repository) PopQueue() (QueueEntry, error) {
+	return takeEntry[QueueEntry](r.db, queueTable)
+}
+
+// RestoreQueueEntry puts an entry taken by PopQueue back at its position, the head of the queue
+// unless entries were queued in front of it meanwhile.
+func (r *Repository) RestoreQueueEntry(e QueueEntry) error {
+	return queueTable.restore(r.db, e.Pos, e.Text, e.QueuedAt)
+}
+
+// Queue returns the entries of the paste queue, next one first.
+func (r *Repository) Queue() ([]QueueEntry, error) {
+	return entries[QueueEntry](r.db, queueTable)
+}
+
+// ClearQueue empties the paste queue and returns how many entries were dropped.
+func (r *Repository) ClearQueue() (int64, error) {
+	return queueTable.clear(r.db)
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPasteQueue(t *testing.T) {
+	repo := setupTestDB(t)
+
+	if err := repo.ReplaceQueue([]string{"old"}); err != nil {
+		t.Fatalf("ReplaceQueue() failed: %v", err)
+	}
+	if err := repo.ReplaceQueue([]string{"user", "p4ss", "host"}); err != nil {
+		t.Fatalf("ReplaceQueue() failed: %v", err)
+	}
+	entries, err := repo.Queue()
+	if err != nil {
+		t.Fatalf("Queue() failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Text != "user" || entries[2].Text != "host" {
+		t.Fatalf("expected the new queue in order, got %+v", entries)
+	}
+
+	// a popped entry is gone until it is restored after a failed paste, then it is next again
+	e, err := repo.PopQueue()
+	if err != nil || e.Text != "user" {
+		t.Fatalf("PopQueue() = %+v, %v, want %q", e, err, "user")
+	}
+	if next, err := repo.PopQueue(); err != nil || next.Text != "p4ss" {
+		t.Fatalf("PopQueue() = %+v, %v, want %q", next, err, "p4ss")
+	}
+	if err = repo.RestoreQueueEntry(e); err != nil {
+		t.Fatalf("RestoreQueueEntry() failed: %v", err)
+	}
+	if entries, err = repo.Queue(); err != nil || len(entries) != 2 || entries[0] != e {
+		t.Fatalf("expected the restored entry at the head, got %+v, %v", entries, err)
+	}
+	if n, err := repo.ClearQueue(); err != nil || n != 2 {
+		t.Fatalf("ClearQueue() = %d, %v, expected 2 dropped", n, err)
+	}
+	if _, err = repo.PopQueue(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound from an empty queue, got %v", err)
+	}
+}
+
+func TestPopQueue_Concurrent(t *testing.T) {
+	repo := setupTestDB(t)
+	// a second connection pool stands for another 'homie paste --next' process
+	other, err := NewRepository(repo.dbPath)
+	if err != nil {
+		t.Fatalf("NewRepository() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = other.Close() })
+
+	texts := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	if err = repo.ReplaceQueue(texts); err != nil {
+		t.Fatalf("ReplaceQueue() failed: %v", err)
+	}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		popped []string
+	)
+	for i := range texts {
+		db := repo
+		if i%2 == 1 {
+			db = other
+		}
+		wg.Go(func() {
+			e, err := db.PopQueue()
+			if err != nil {
+				t.Errorf("PopQueue() failed: %v", err)
+				return
+			}
+			mu.Lock()
+			popped = append(popped, e.Text)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	slices.Sort(popped)
+	if !slices.Equal(popped, texts) {
+		t.Errorf("expected every entry popped exactly once, got %v", popped)
+	}
+}
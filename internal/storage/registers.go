@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxNameLen bounds the length of register and snippet names in characters.
+const maxNameLen = 64
+
+// Register is a named slot kept apart from the history stream, e.g. "ticket" or a vim-style "a".
+// Retention clean-up and clearing the history never touch registers.
+type Register struct {
+	Name      string    `db:"name"`
+	Text      string    `db:"clip_text"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ValidateRegisterName accepts names of up to 64 characters without whitespace or control characters.
+func ValidateRegisterName(name string) error {
+	return validateName("register", name)
+}
+
+func validateName(kind, name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%s name is empty", kind)
+	case utf8.RuneCountInString(name) > maxNameLen:
+		return fmt.Errorf("%s name %q is longer than %d characters", kind, name, maxNameLen)
+	case strings.ContainsFunc(name, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }):
+		return fmt.Errorf("%s name %q contains whitespace or control characters", kind, name)
+	}
+	return nil
+}
+
+func (r *Repository) migrateRegisters() error {
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS registers (
+			name TEXT PRIMARY KEY,
+			clip_text TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create registers table: %w", err)
+	}
+	return nil
+}
+
+// SetRegister stores text in the register name, replacing its previous content.
+func (r *Repository) SetRegister(name, text string) error {
+	if err := ValidateRegisterName(name); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO registers (name, clip_text, updated_at) 
+		VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET clip_text = excluded.clip_text, updated_at = excluded.updated_at
+	`, name, text, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set register %q: %w", name, err)
+	}
+	return nil
+}
+
+// GetRegister returns the register name.
+func (r *Repository) GetRegister(name string) (Register, error) {
+	var reg Register
+	err := r.db.Get(&reg, `SELECT name, clip_text, updated_at FROM registers WHERE name = ?`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Register{}, fmt.Errorf("failed to get register %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return Register{}, fmt.Errorf("failed to get register %q: %w", name, err)
+	}
+	return reg, nil
+}
+
+// Registers returns all registers ordered by name.
+func (r *Repository) Registers() ([]Register, error) {
+	var regs []Register
+	if err := r.db.Select(&regs, `SELECT name, clip_text, updated_at FROM registers ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to read registers: %w", err)
+	}
+	return regs, nil
+}
+
+// DeleteRegister removes the register name.
+func (r *Repository) DeleteRegister(name string) error {
+	res, err := r.db.Exec(`DELETE FROM registers WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete register %q: %w", name, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to delete register %q: %w", name, ErrNotFound)
+	}
+	return nil
+}
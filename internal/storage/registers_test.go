@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisters(t *testing.T) {
+	repo := setupTestDB(t)
+
+	for name, text := range map[string]string{"b": "second", "a": "first", "ticket": "HOM-42"} {
+		if err := repo.SetRegister(name, text); err != nil {
+			t.Fatalf("SetRegister(%q) failed: %v", name, err)
+		}
+	}
+	if err := repo.SetRegister("a", "first, updated"); err != nil {
+		t.Fatalf("SetRegister() overwrite failed: %v", err)
+	}
+
+	reg, err := repo.GetRegister("a")
+	if err != nil {
+		t.Fatalf("GetRegister() failed: %v", err)
+	}
+	if reg.Text != "first, updated" || reg.UpdatedAt.IsZero() {
+		t.Errorf("unexpected register %+v", reg)
+	}
+
+	regs, err := repo.Registers()
+	if err != nil {
+		t.Fatalf("Registers() failed: %v", err)
+	}
+	var names []string
+	for _, r := range regs {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "a,b,ticket" {
+		t.Errorf("expected registers ordered by name, got %s", got)
+	}
+
+	if err = repo.DeleteRegister("b"); err != nil {
+		t.Fatalf("DeleteRegister() failed: %v", err)
+	}
+	if _, err = repo.GetRegister("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err = repo.DeleteRegister("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting a missing register, got %v", err)
+	}
+}
+
+func TestRegisters_SurviveHistoryCleanUp(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 5)
+	if err := repo.SetRegister("a", "kept"); err != nil {
+		t.Fatalf("SetRegister() failed: %v", err)
+	}
+
+	if err := CleanOldHistory(repo, CleanupConfig{CleanUp: true, MaxSize: 1, Limit: 1}); err != nil {
+		t.Fatalf("CleanOldHistory() failed: %v", err)
+	}
+	if err := repo.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+	if reg, err := repo.GetRegister("a"); err != nil || reg.Text != "kept" {
+		t.Errorf("expected the register to survive, got %+v, %v", reg, err)
+	}
+}
+
+func TestValidateRegisterName(t *testing.T) {
+	for _, name := range []string{"a", "ticket", "prod-db.host", "ä"} {
+		if err := ValidateRegisterName(name); err != nil {
+			t.Errorf("ValidateRegisterName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "two words", "tab\tname", strings.Repeat("x", 65)} {
+		if err := ValidateRegisterName(name); err == nil {
+			t.Errorf("expected ValidateRegisterName(%q) to fail", name)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Snippet is a named, tagged text the user keeps for reuse, e.g. a deploy command with placeholders.
+// Saved snippets live in the database only; snippets loaded from the snippet directory record their file as Source.
+type Snippet struct {
+	Name      string
+	Text      string
+	Tags      []string
+	Source    string
+	UpdatedAt time.Time
+}
+
+// snippetRow is the stored form of a Snippet; tags are kept as a comma-separated list.
+type snippetRow struct {
+	Name      string    `db:"name"`
+	Text      string    `db:"body"`
+	Tags      string    `db:"tags"`
+	Source    string    `db:"source"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (row snippetRow) snippet() Snippet {
+	s := Snippet{Name: row.Name, Text: row.Text, Source: row.Source, UpdatedAt: row.UpdatedAt}
+	if row.Tags != "" {
+		s.Tags = strings.Split(row.Tags, ",")
+	}
+	return s
+}
+
+// ValidateSnippetName accepts names of up to 64 characters without whitespace or control characters.
+func ValidateSnippetName(name string) error {
+	return validateName("snippet", name)
+}
+
+// NormalizeTags trims, lowercases, de-duplicates and sorts tags; commas split a tag into several.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		for t := range strings.SplitSeq(tag, ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				out = append(out, t)
+			}
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+func (r *Repository) migrateSnippets() error {
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS snippets (
+			name TEXT PRIMARY KEY,
+			body TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create snippets table: %w", err)
+	}
+	return nil
+}
+
+// SaveSnippet stores s as a saved snippet, replacing a snippet of the same name (including one loaded from a file).
+func (r *Repository) SaveSnippet(s Snippet) error {
+	if err := ValidateSnippetName(s.Name); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO snippets (name, body, tags, source, updated_at)
+		VALUES (?, ?, ?, '', ?)
+		ON CONFLICT (name) DO UPDATE
+		SET body = excluded.body, tags = excluded.tags, source = '', updated_at = excluded.updated_at
+	`, s.Name, s.Text, strings.Join(NormalizeTags(s.Tags), ","), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save snippet %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// Snippet returns the snippet name.
+func (r *Repository) Snippet(name string) (Snippet, error) {
+	var row snippetRow
+	err := r.db.Get(&row, `SELECT name, body, tags, source, updated_at FROM snippets WHERE name = ?`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Snippet{}, fmt.Errorf("failed to get snippet %q: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return Snippet{}, fmt.Errorf("failed to get snippet %q: %w", name, err)
+	}
+	return row.snippet(), nil
+}
+
+// Snippets returns the snippets tagged with every tag in tags (all for none), ordered by name.
+func (r *Repository) Snippets(tags ...string) ([]Snippet, error) {
+	var rows []snippetRow
+	if err := r.db.Select(&rows, `SELECT name, body, tags, source, updated_at FROM snippets ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to read snippets: %w", err)
+	}
+	tags = NormalizeTags(tags)
+	snippets := make([]Snippet, 0, len(rows))
+	for _, row := range rows {
+		s := row.snippet()
+		if !slices.ContainsFunc(tags, func(t string) bool { return !slices.Contains(s.Tags, t) }) {
+			snippets = append(snippets, s)
+		}
+	}
+	return snippets, nil
+}
+
+// DeleteSnippet removes the saved snippet name. Snippets loaded from files are removed by editing the file.
+func (r *Repository) DeleteSnippet(name string) error {
+	s, err := r.Snippet(name)
+	if err != nil {
+		return err
+	}
+	if s.Source != "" {
+		return fmt.Errorf("snippet %q comes from %s, remove it there", name, s.Source)
+	}
+	if _, err = r.db.Exec(`DELETE FROM snippets WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete snippet %q: %w", name, err)
+	}
+	return nil
+}
+
+// SyncFileSnippets replaces the snippets loaded from files with snippets.
+// Saved snippets take precedence; the names of file snippets shadowed by them are returned.
+func (r *Repository) SyncFileSnippets(snippets []Snippet) (shadowed []string, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snippet sync: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM snippets WHERE source != ''`); err != nil {
+		return nil, fmt.Errorf("failed to remove file snippets: %w", err)
+	}
+	now := time.Now()
+	for _, s := range snippets {
+		if err = ValidateSnippetName(s.Name); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Source, err)
+		}
+		res, err := tx.Exec(`
+			INSERT INTO snippets (name, body, tags, source, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (name) DO NOTHING
+		`, s.Name, s.Text, strings.Join(NormalizeTags(s.Tags), ","), s.Source, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store snippet %q from %s: %w", s.Name, s.Source, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			shadowed = append(shadowed, s.Name)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit snippet sync: %w", err)
+	}
+	return shadowed, nil
+}
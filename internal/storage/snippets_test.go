@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func snippetNames(snippets []Snippet) string {
+	names := make([]string, 0, len(snippets))
+	for _, s := range snippets {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSnippets(t *testing.T) {
+	repo := setupTestDB(t)
+
+	if err := repo.SaveSnippet(Snippet{Name: "deploy", Text: "make deploy ENV={{prompt \"env\"}}", Tags: []string{"Ops, k8s", "ops"}}); err != nil {
+		t.Fatalf("SaveSnippet() failed: %v", err)
+	}
+	if err := repo.SaveSnippet(Snippet{Name: "sig", Text: "Regards"}); err != nil {
+		t.Fatalf("SaveSnippet() failed: %v", err)
+	}
+
+	s, err := repo.Snippet("deploy")
+	if err != nil {
+		t.Fatalf("Snippet() failed: %v", err)
+	}
+	if !slices.Equal(s.Tags, []string{"k8s", "ops"}) || s.Source != "" || s.UpdatedAt.IsZero() {
+		t.Errorf("unexpected snippet %+v", s)
+	}
+
+	all, err := repo.Snippets()
+	if err != nil {
+		t.Fatalf("Snippets() failed: %v", err)
+	}
+	if got := snippetNames(all); got != "deploy,sig" {
+		t.Errorf("expected all snippets by name, got %s", got)
+	}
+	tagged, err := repo.Snippets("OPS", "k8s")
+	if err != nil {
+		t.Fatalf("Snippets(tags) failed: %v", err)
+	}
+	if got := snippetNames(tagged); got != "deploy" {
+		t.Errorf("expected the snippets with both tags, got %s", got)
+	}
+
+	if err = repo.DeleteSnippet("sig"); err != nil {
+		t.Fatalf("DeleteSnippet() failed: %v", err)
+	}
+	if _, err = repo.Snippet("sig"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err = repo.SaveSnippet(Snippet{Name: "no name"}); err == nil {
+		t.Error("expected an invalid name to be rejected")
+	}
+}
+
+func TestSyncFileSnippets(t *testing.T) {
+	repo := setupTestDB(t)
+	if err := repo.SaveSnippet(Snippet{Name: "deploy", Text: "saved"}); err != nil {
+		t.Fatalf("SaveSnippet() failed: %v", err)
+	}
+
+	shadowed, err := repo.SyncFileSnippets([]Snippet{
+		{Name: "deploy", Text: "from file", Source: "ops.yaml"},
+		{Name: "greet", Text: "hello", Source: "ops.yaml"},
+		{Name: "old", Text: "gone soon", Source: "old.toml"},
+	})
+	if err != nil {
+		t.Fatalf("SyncFileSnippets() failed: %v", err)
+	}
+	if !slices.Equal(shadowed, []string{"deploy"}) {
+		t.Errorf("expected the saved snippet to shadow the file one, got %v", shadowed)
+	}
+	if s, _ := repo.Snippet("deploy"); s.Text != "saved" {
+		t.Errorf("expected the saved snippet to stay, got %+v", s)
+	}
+	if err = repo.DeleteSnippet("greet"); err == nil || !strings.Contains(err.Error(), "ops.yaml") {
+		t.Errorf("expected file snippets to be read-only, got %v", err)
+	}
+
+	// a file was removed -> its snippets go away on the next sync
+	if _, err = repo.SyncFileSnippets([]Snippet{{Name: "greet", Text: "hi", Source: "ops.yaml"}}); err != nil {
+		t.Fatalf("SyncFileSnippets() failed: %v", err)
+	}
+	all, _ := repo.Snippets()
+	if got := snippetNames(all); got != "deploy,greet" {
+		t.Errorf("unexpected snippets after resync: %s", got)
+	}
+	if s, _ := repo.Snippet("greet"); s.Text != "hi" || s.Source != "ops.yaml" {
+		t.Errorf("expected the updated file snippet, got %+v", s)
+	}
+}
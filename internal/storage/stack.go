@@ -0,0 +1,45 @@
+package storage
+
+import "time"
+
+// StackEntry is a text saved on the clipboard stack ('homie push'); ID orders the stack.
+type StackEntry struct {
+	ID       int       `db:"id"`
+	Text     string    `db:"clip_text"`
+	PushedAt time.Time `db:"pushed_at"`
+}
+
+func (r *Repository) migrateStack() error {
+	return stackTable.migrate(r.db)
+}
+
+// PushStack puts text on top of the clipboard stack and returns the new depth of the stack.
+// Like registers the stack lives apart from the history, so the clean-up never touches it.
+func (r *Repository) PushStack(text string) (int, error) {
+	if err := stackTable.add(r.db, text, time.Now()); err != nil {
+		return 0, err
+	}
+	return stackTable.count(r.db)
+}
+
+// PopStack takes the top entry off the clipboard stack and returns it; ErrNotFound when it is empty.
+// The entry is claimed at once, so two pops never get the same one; RestoreStackEntry puts it back
+// when it can't be restored to the clipboard.
+func (r *Repository) PopStack() (StackEntry, error) {
+	return takeEntry[StackEntry](r.db, stackTable)
+}
+
+// RestoreStackEntry puts an entry taken by PopStack back at its place, below the entries pushed meanwhile.
+func (r *Repository) RestoreStackEntry(e StackEntry) error {
+	return stackTable.restore(r.db, e.ID, e.Text, e.PushedAt)
+}
+
+// Stack returns the entries of the clipboard stack, top first.
+func (r *Repository) Stack() ([]StackEntry, error) {
+	return entries[StackEntry](r.db, stackTable)
+}
+
+// ClearStack empties the clipboard stack and returns how many entries were dropped.
+func (r *Repository) ClearStack() (int64, error) {
+	return stackTable.clear(r.db)
+}
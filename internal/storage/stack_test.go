@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	repo := setupTestDB(t)
+
+	for i, text := range []string{"saved", "", "temp"} {
+		depth, err := repo.PushStack(text)
+		if err != nil || depth != i+1 {
+			t.Fatalf("PushStack(%q) = %d, %v, want depth %d", text, depth, err, i+1)
+		}
+	}
+	entries, err := repo.Stack()
+	if err != nil {
+		t.Fatalf("Stack() failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Text != "temp" || entries[2].Text != "saved" {
+		t.Fatalf("expected the stack top first, got %+v", entries)
+	}
+
+	top, err := repo.PopStack()
+	if err != nil || top.Text != "temp" {
+		t.Fatalf("PopStack() = %+v, %v, want %q", top, err, "temp")
+	}
+	// a restored entry goes back to its place, below the entries pushed meanwhile
+	if _, err = repo.PushStack("newer"); err != nil {
+		t.Fatalf("PushStack() failed: %v", err)
+	}
+	if err = repo.RestoreStackEntry(top); err != nil {
+		t.Fatalf("RestoreStackEntry() failed: %v", err)
+	}
+	for _, want := range []string{"newer", "temp", ""} {
+		if e, err := repo.PopStack(); err != nil || e.Text != want {
+			t.Fatalf("PopStack() = %+v, %v, want %q", e, err, want)
+		}
+	}
+	if n, err := repo.ClearStack(); err != nil || n != 1 {
+		t.Fatalf("ClearStack() = %d, %v, expected 1 dropped", n, err)
+	}
+	if _, err = repo.PopStack(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound from an empty stack, got %v", err)
+	}
+}
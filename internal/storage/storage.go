@@ -7,11 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/kaliv0/homie/internal/content"
 )
 
 const (
@@ -29,12 +31,34 @@ const (
 
 const dbFilePerm = 0o600
 
+// ErrNotFound is returned when a requested clipboard item or register doesn't exist.
+var ErrNotFound = errors.New("not found")
+
 // ClipboardItem represents a clipboard entry persisted in the database.
 type ClipboardItem struct {
 	ID        int       `db:"id"`
 	ClipText  string    `db:"clip_text"`
 	TextHash  string    `db:"text_hash"`
 	TimeStamp time.Time `db:"time_stamp"`
+	CopyCount int       `db:"copy_count"`
+	Pinned    bool      `db:"pinned"`
+	// ContentType is the content.Type detected when the item was captured.
+	ContentType string `db:"content_type"`
+	// Note is a free-text remark of the user, Tags are labels like "prod" or "ticket-123".
+	Note string  `db:"note"`
+	Tags TagList `db:"tags"`
+}
+
+// itemColumns are the columns scanned into a ClipboardItem.
+const itemColumns = "id, clip_text, text_hash, time_stamp, copy_count, pinned, content_type, note, " +
+	"(SELECT group_concat(tag) FROM item_tags WHERE item_id = clipboard_items.id) AS tags"
+
+// Type returns the stored content type, classifying the text when it is missing.
+func (item ClipboardItem) Type() content.Type {
+	if item.ContentType != "" {
+		return content.Type(item.ContentType)
+	}
+	return content.Classify(item.ClipText)
 }
 
 // Repository wraps database access for clipboard items.
@@ -46,7 +70,7 @@ type Repository struct {
 // NewRepository opens the SQLite database at dbPath.
 func NewRepository(dbPath string) (*Repository, error) {
 	// create db if not exists
-	db, err := sqlx.Connect("sqlite3", dbPath)
+	db, err := sqlx.Connect(driverName, dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database at %q: %w", dbPath, err)
 	}
@@ -80,26 +104,119 @@ func NewRepository(dbPath string) (*Repository, error) {
 	return &Repository{db, dbPath}, nil
 }
 
-// AutoMigrate creates the clipboard_items table if it doesn't exist.
+// Open opens the database at dbPath and brings its schema up to date,
+// so commands work before the daemon has migrated the database.
+func Open(dbPath string) (*Repository, error) {
+	r, err := NewRepository(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.AutoMigrate(); err != nil {
+		return nil, errors.Join(err, r.Close())
+	}
+	return r, nil
+}
+
+// AutoMigrate creates the clipboard_items, meta, item_tags, paste_queue, clip_stack, registers and snippets tables if they don't exist and adds missing columns.
 func (r *Repository) AutoMigrate() error {
 	_, err := r.db.Exec(`
 		CREATE TABLE IF NOT EXISTS clipboard_items (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			clip_text TEXT NOT NULL,
 			text_hash TEXT NOT NULL,
-			time_stamp DATETIME NOT NULL
+			time_stamp DATETIME NOT NULL,
+			copy_count INTEGER NOT NULL DEFAULT 1,
+			pinned INTEGER NOT NULL DEFAULT 0,
+			content_type TEXT NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT ''
 		)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to create clipboard_items table: %w", err)
 	}
+	// columns added after the initial schema -> upgrade existing databases in place
+	if err = r.ensureColumn("clipboard_items", "copy_count", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err = r.ensureColumn("clipboard_items", "pinned", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err = r.ensureColumn("clipboard_items", "content_type", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err = r.ensureColumn("clipboard_items", "note", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err = r.classifyItems(); err != nil {
+		return err
+	}
 	// Create index on time_stamp for better query performance
 	_, err = r.db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_time_stamp ON clipboard_items(time_stamp);
-		CREATE INDEX IF NOT EXISTS idx_text_hash ON clipboard_items(text_hash)
+		CREATE INDEX IF NOT EXISTS idx_text_hash ON clipboard_items(text_hash);
+		CREATE INDEX IF NOT EXISTS idx_content_type ON clipboard_items(content_type)
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to create index idx_time_stamp on clipboard_items: %w", err)
+		return fmt.Errorf("failed to create indexes on clipboard_items: %w", err)
+	}
+	if err = r.migrateTags(); err != nil {
+		return err
+	}
+	if err = r.migrateQueue(); err != nil {
+		return err
+	}
+	if err = r.migrateStack(); err != nil {
+		return err
+	}
+	if err = r.migrateRegisters(); err != nil {
+		return err
+	}
+	return r.migrateSnippets()
+}
+
+// classifyItems fills in content_type for items captured before the column existed and,
+// after the classifier changed (content.ClassifierVersion), recomputes it for all items.
+// The version applied last is kept in the meta table.
+func (r *Repository) classifyItems() error {
+	if _, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to create meta table: %w", err)
+	}
+	var version int
+	err := r.db.Get(&version, `SELECT value FROM meta WHERE key = 'classifier_version'`)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read classifier version: %w", err)
+	}
+	if version == content.ClassifierVersion {
+		if _, err = r.db.Exec(`UPDATE clipboard_items SET content_type = classify(clip_text) WHERE content_type = ''`); err != nil {
+			return fmt.Errorf("failed to backfill content_type: %w", err)
+		}
+		return nil
+	}
+
+	if _, err = r.db.Exec(`UPDATE clipboard_items SET content_type = classify(clip_text)`); err != nil {
+		return fmt.Errorf("failed to reclassify clipboard items: %w", err)
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO meta (key, value) VALUES ('classifier_version', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`, content.ClassifierVersion)
+	if err != nil {
+		return fmt.Errorf("failed to store classifier version: %w", err)
+	}
+	return nil
+}
+
+// ensureColumn adds column to table unless it already exists.
+func (r *Repository) ensureColumn(table, column, definition string) error {
+	var columns []string
+	if err := r.db.Select(&columns, `SELECT name FROM pragma_table_info(?)`, table); err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	if slices.Contains(columns, column) {
+		return nil
+	}
+	if _, err := r.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s to %s: %w", column, table, err)
 	}
 	return nil
 }
@@ -122,66 +239,162 @@ func (r *Repository) SetDBFilesPermissions() error {
 
 // Read returns clipboard items ordered by timestamp descending.
 func (r *Repository) Read(offset, limit int) ([]ClipboardItem, error) {
-	var items []ClipboardItem
-	err := r.db.Select(&items, `
-		SELECT id, clip_text, text_hash, time_stamp 
+	return r.Search(Filter{}, offset, limit)
+}
+
+// ReadAfter returns up to limit clipboard items that come after cursor.
+func (r *Repository) ReadAfter(cursor Cursor, limit int) ([]ClipboardItem, error) {
+	return r.SearchAfter(Filter{}, cursor, limit)
+}
+
+// Get returns the record with the given id.
+func (r *Repository) Get(id int) (ClipboardItem, error) {
+	var item ClipboardItem
+	err := r.db.Get(&item, `
+		SELECT `+itemColumns+` 
 		FROM clipboard_items 
-		ORDER BY time_stamp DESC 
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+		WHERE id = ?
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ClipboardItem{}, fmt.Errorf("failed to get clipboard item (id=%d): %w", id, ErrNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read clipboard items (offset=%d, limit=%d): %w", offset, limit, err)
+		return ClipboardItem{}, fmt.Errorf("failed to get clipboard item (id=%d): %w", id, err)
 	}
-	return items, nil
+	return item, nil
 }
 
-// Write inserts a new clipboard item or updates timestamp if it already exists.
+// Nth returns the n-th most recent record (1 -> newest).
+func (r *Repository) Nth(n int) (ClipboardItem, error) {
+	if n < 1 {
+		return ClipboardItem{}, fmt.Errorf("invalid position %d, the newest item is 1", n)
+	}
+	items, err := r.Read(n-1, 1)
+	if err != nil {
+		return ClipboardItem{}, err
+	}
+	if len(items) == 0 {
+		return ClipboardItem{}, fmt.Errorf("failed to get clipboard item (position=%d): %w", n, ErrNotFound)
+	}
+	return items[0], nil
+}
+
+// TextHash returns the hash that identifies item in history.
+func TextHash(item []byte) string {
+	sum := sha256.Sum256(item)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write inserts a new clipboard item or, if it already exists, updates its timestamp and bumps its copy count.
 func (r *Repository) Write(item []byte) error {
-	hasher := sha256.New()
-	if _, err := hasher.Write(item); err != nil {
-		return fmt.Errorf("failed to hash clipboard item (length=%d): %w", len(item), err)
+	_, err := write(r.db, item)
+	return err
+}
+
+// WriteTagged writes item like Write and adds tags to it; tags are normalized like NormalizeTags.
+// Invalid tags are reported before anything is written, and the item is stored with its tags or not at all.
+func (r *Repository) WriteTagged(item []byte, tags []string) (err error) {
+	tags = NormalizeTags(tags)
+	if err = validateTags(tags); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin clipboard item write: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	id, err := write(tx, item)
+	if err != nil {
+		return err
+	}
+	if err = insertTags(tx, id, tags); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit clipboard item (id=%d): %w", id, err)
 	}
-	textHash := hex.EncodeToString(hasher.Sum(nil))
+	return nil
+}
+
+// write stores item through q (the database or a transaction) and returns its id.
+func write(q sqlx.Ext, item []byte) (int, error) {
+	textHash := TextHash(item)
 
 	var existingItem ClipboardItem
-	err := r.db.Get(&existingItem, `
+	err := sqlx.Get(q, &existingItem, `
 		SELECT id, clip_text, text_hash, time_stamp 
 		FROM clipboard_items 
 		WHERE text_hash = ?
 	`, textHash)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		_, err = r.db.Exec(`
-			INSERT INTO clipboard_items (clip_text, text_hash, time_stamp)
-			VALUES (?, ?, ?)
-		`, string(item), textHash, time.Now())
+		var id int
+		err = sqlx.Get(q, &id, `
+			INSERT INTO clipboard_items (clip_text, text_hash, time_stamp, content_type)
+			VALUES (?, ?, ?, ?)
+			RETURNING id
+		`, string(item), textHash, time.Now(), content.Classify(string(item)))
 		if err != nil {
-			return fmt.Errorf("failed to insert clipboard item (hash=%s, length=%d): %w", textHash, len(item), err)
+			return 0, fmt.Errorf("failed to insert clipboard item (hash=%s, length=%d): %w", textHash, len(item), err)
 		}
-		return nil
+		return id, nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to check for existing clipboard item (hash=%s): %w", textHash, err)
+		return 0, fmt.Errorf("failed to check for existing clipboard item (hash=%s): %w", textHash, err)
 	}
 
-	_, err = r.db.Exec(`
+	_, err = q.Exec(`
 		UPDATE clipboard_items 
-		SET time_stamp = ? 
+		SET time_stamp = ?, copy_count = copy_count + 1 
 		WHERE id = ?
 	`, time.Now(), existingItem.ID)
 	if err != nil {
-		return fmt.Errorf("failed to update timestamp for clipboard item (id=%d, hash=%s): %w",
+		return 0, fmt.Errorf("failed to update timestamp for clipboard item (id=%d, hash=%s): %w",
 			existingItem.ID, textHash, err)
 	}
+	return existingItem.ID, nil
+}
+
+// Delete removes the record with the given id.
+func (r *Repository) Delete(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM clipboard_items WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete clipboard item (id=%d): %w", id, err)
+	}
 	return nil
 }
 
-// DeleteExcess removes the oldest records.
+// TogglePin flips the pinned flag of a record and returns the new state.
+// Pinned records are never removed by the history clean-up.
+func (r *Repository) TogglePin(id int) (bool, error) {
+	var pinned bool
+	err := r.db.Get(&pinned, `
+		UPDATE clipboard_items 
+		SET pinned = NOT pinned 
+		WHERE id = ? 
+		RETURNING pinned
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("failed to toggle pin (id=%d): %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to toggle pin of clipboard item (id=%d): %w", id, err)
+	}
+	return pinned, nil
+}
+
+// DeleteExcess removes the oldest unpinned records.
 func (r *Repository) DeleteExcess(deleteCount int) error {
 	_, err := r.db.Exec(`
 		DELETE FROM clipboard_items 
 		WHERE id IN (
 			SELECT id FROM clipboard_items 
+			WHERE pinned = 0 
 			ORDER BY time_stamp 
 			LIMIT ?
 		)
@@ -192,11 +405,11 @@ func (r *Repository) DeleteExcess(deleteCount int) error {
 	return nil
 }
 
-// DeleteOldest removes records older than the given TTL.
+// DeleteOldest removes unpinned records older than the given TTL.
 func (r *Repository) DeleteOldest(ttl int) error {
 	_, err := r.db.Exec(`
 		DELETE FROM clipboard_items
-		WHERE time_stamp < datetime('now', concat(?, ' days'), 'localtime')
+		WHERE pinned = 0 AND time_stamp < datetime('now', concat(?, ' days'), 'localtime')
 	`, "-"+strconv.Itoa(ttl))
 	if err != nil {
 		return fmt.Errorf("failed to delete oldest clipboard items (ttl=%d days): %w", ttl, err)
@@ -261,9 +474,24 @@ func CleanOldHistory(db *Repository, cfg CleanupConfig) error {
 	if err != nil {
 		return err
 	}
-
-	if total <= maxSize || minLimit >= total {
+	if total <= maxSize {
 		return nil
 	}
-	return db.DeleteExcess(total - minLimit)
+	// pinned items are kept on top of the newest minLimit unpinned ones, since DeleteExcess skips them
+	unpinned, err := db.countUnpinned()
+	if err != nil {
+		return err
+	}
+	if unpinned <= minLimit {
+		return nil
+	}
+	return db.DeleteExcess(unpinned - minLimit)
+}
+
+func (r *Repository) countUnpinned() (int, error) {
+	var count int
+	if err := r.db.Get(&count, `SELECT COUNT(*) FROM clipboard_items WHERE pinned = 0`); err != nil {
+		return 0, fmt.Errorf("failed to count unpinned clipboard items: %w", err)
+	}
+	return count, nil
 }
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -115,6 +116,120 @@ func TestAutoMigrate(t *testing.T) {
 	}
 }
 
+func TestAutoMigrate_AddsCopyCountToExistingTable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+	repo, err := NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewRepository(%q) failed: %v", dbPath, err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	// schema before copy_count was introduced
+	_, err = repo.db.Exec(`
+		CREATE TABLE clipboard_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			clip_text TEXT NOT NULL,
+			text_hash TEXT NOT NULL,
+			time_stamp DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+	insertOldItem(t, repo, "legacy", "hash-legacy", 0)
+
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate() failed: %v", err)
+	}
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("second AutoMigrate() failed: %v", err)
+	}
+
+	items := mustRead(t, repo, 0, 10)
+	if len(items) != 1 || items[0].CopyCount != 1 {
+		t.Fatalf("expected legacy item with copy_count=1, got %+v", items)
+	}
+}
+
+func TestAutoMigrate_BackfillsContentType(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+	repo, err := NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewRepository(%q) failed: %v", dbPath, err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	// schema before content_type was introduced
+	_, err = repo.db.Exec(`
+		CREATE TABLE clipboard_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			clip_text TEXT NOT NULL,
+			text_hash TEXT NOT NULL,
+			time_stamp DATETIME NOT NULL,
+			copy_count INTEGER NOT NULL DEFAULT 1,
+			pinned INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+	insertOldItem(t, repo, "https://example.com", "hash-url", 1)
+	insertOldItem(t, repo, "192.168.0.1", "hash-ip", 0)
+
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate() failed: %v", err)
+	}
+
+	items := mustRead(t, repo, 0, 10)
+	if len(items) != 2 || items[0].ContentType != "ip" || items[1].ContentType != "url" {
+		t.Fatalf("expected backfilled content types, got %+v", items)
+	}
+}
+
+func TestAutoMigrate_ReclassifiesOnClassifierChange(t *testing.T) {
+	repo := setupTestDB(t)
+	path := "/tmp/TestRun3859135101/001"
+	if err := repo.Write([]byte(path)); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	// stored by an older classifier that took such paths for secrets
+	if _, err := repo.db.Exec(`UPDATE clipboard_items SET content_type = 'secret'`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate() failed: %v", err)
+	}
+	if items := mustRead(t, repo, 0, 10); items[0].ContentType != "secret" {
+		t.Fatalf("expected no reclassification with the same classifier, got %q", items[0].ContentType)
+	}
+
+	if _, err := repo.db.Exec(`UPDATE meta SET value = value - 1 WHERE key = 'classifier_version'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate() failed: %v", err)
+	}
+	if items := mustRead(t, repo, 0, 10); items[0].ContentType != "path" {
+		t.Errorf("expected the item reclassified as path, got %q", items[0].ContentType)
+	}
+}
+
+func TestWrite_StoresContentType(t *testing.T) {
+	repo := setupTestDB(t)
+	if err := repo.Write([]byte("jane@example.org")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	item := mustRead(t, repo, 0, 1)[0]
+	if item.ContentType != "email" || item.Type() != "email" {
+		t.Errorf("expected content type email, got %q", item.ContentType)
+	}
+	if got := (ClipboardItem{ClipText: "#fff"}).Type(); got != "color" {
+		t.Errorf("expected Type() to classify items without a stored type, got %q", got)
+	}
+}
+
 func TestSetDBFilesPermissions(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip()
@@ -225,6 +340,21 @@ func TestWrite_DuplicateUpdatesTimestamp(t *testing.T) {
 	}
 }
 
+func TestWrite_DuplicateIncrementsCopyCount(t *testing.T) {
+	repo := setupTestDB(t)
+
+	for range 3 {
+		if err := repo.Write([]byte("again")); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	items := mustRead(t, repo, 0, 10)
+	if len(items) != 1 || items[0].CopyCount != 3 {
+		t.Fatalf("expected 1 item with copy_count=3, got %+v", items)
+	}
+}
+
 func TestWrite_MultipleUniqueItems(t *testing.T) {
 	repo := setupTestDB(t)
 	for i := range 20 {
@@ -376,6 +506,75 @@ func TestDeleteExcess(t *testing.T) {
 	}
 }
 
+func TestDelete(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 3)
+
+	items := mustRead(t, repo, 0, 10)
+	if err := repo.Delete(items[1].ID); err != nil {
+		t.Fatalf("Delete(%d) failed: %v", items[1].ID, err)
+	}
+
+	left := mustRead(t, repo, 0, 10)
+	if len(left) != 2 || left[0].ID != items[0].ID || left[1].ID != items[2].ID {
+		t.Errorf("expected items %d and %d to remain, got %+v", items[0].ID, items[2].ID, left)
+	}
+}
+
+func TestTogglePin(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 1)
+	id := mustRead(t, repo, 0, 1)[0].ID
+
+	for _, want := range []bool{true, false} {
+		pinned, err := repo.TogglePin(id)
+		if err != nil {
+			t.Fatalf("TogglePin(%d) failed: %v", id, err)
+		}
+		if pinned != want {
+			t.Errorf("expected pinned=%v, got %v", want, pinned)
+		}
+		if got := mustRead(t, repo, 0, 1)[0].Pinned; got != want {
+			t.Errorf("expected stored pinned=%v, got %v", want, got)
+		}
+	}
+}
+
+func TestTogglePin_Missing(t *testing.T) {
+	repo := setupTestDB(t)
+
+	if _, err := repo.TogglePin(42); err == nil {
+		t.Fatal("expected error for missing item, got nil")
+	}
+}
+
+func TestCleanUp_KeepsPinned(t *testing.T) {
+	repo := setupTestDB(t)
+	insertOldItem(t, repo, "old pinned", "hash-old-pinned", 30)
+	insertOldItem(t, repo, "old", "hash-old", 30)
+
+	items := mustRead(t, repo, 0, 10)
+	for _, item := range items {
+		if item.ClipText == "old pinned" {
+			if _, err := repo.TogglePin(item.ID); err != nil {
+				t.Fatalf("TogglePin(%d) failed: %v", item.ID, err)
+			}
+		}
+	}
+
+	if err := repo.DeleteOldest(7); err != nil {
+		t.Fatalf("DeleteOldest() failed: %v", err)
+	}
+	if err := repo.DeleteExcess(10); err != nil {
+		t.Fatalf("DeleteExcess() failed: %v", err)
+	}
+
+	left := mustRead(t, repo, 0, 10)
+	if len(left) != 1 || left[0].ClipText != "old pinned" {
+		t.Errorf("expected only the pinned item to remain, got %+v", left)
+	}
+}
+
 func TestDeleteOldest(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -521,6 +720,24 @@ func TestCleanOldHistory_MaxSize(t *testing.T) {
 	}
 }
 
+func TestCleanOldHistory_MaxSizeKeepsLimitBesidesPins(t *testing.T) {
+	t.Parallel()
+	repo := setupTestDB(t)
+	seedItems(t, repo, 10)
+	for _, id := range []int{1, 2, 3} {
+		if _, err := repo.TogglePin(id); err != nil {
+			t.Fatalf("TogglePin(%d) failed: %v", id, err)
+		}
+	}
+
+	cfg := CleanupConfig{CleanUp: true, MaxSize: 8, Limit: 5}
+	if err := CleanOldHistory(repo, cfg); err != nil {
+		t.Fatalf("CleanOldHistory() failed: %v", err)
+	}
+	// the 3 pins plus the 5 newest unpinned items
+	assertCount(t, repo, 8)
+}
+
 func TestCleanOldHistory_DefaultsWhenZeroOrNegative(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -615,3 +832,55 @@ func TestCleanOldHistory_MaxSizeKeepsNewestRows(t *testing.T) {
 		}
 	}
 }
+
+func TestOpen_Migrates(t *testing.T) {
+	repo, err := Open(filepath.Join(t.TempDir(), "fresh.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	if err := repo.Write([]byte("first")); err != nil {
+		t.Fatalf("Write() after Open() failed: %v", err)
+	}
+	assertCount(t, repo, 1)
+}
+
+func TestGet(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 3)
+	want := mustRead(t, repo, 1, 1)[0]
+
+	got, err := repo.Get(want.ID)
+	if err != nil {
+		t.Fatalf("Get(%d) failed: %v", want.ID, err)
+	}
+	if got.ID != want.ID || got.ClipText != want.ClipText {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := repo.Get(999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing id, got %v", err)
+	}
+}
+
+func TestNth(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 3)
+
+	for n, want := range map[int]string{1: "item-2", 2: "item-1", 3: "item-0"} {
+		item, err := repo.Nth(n)
+		if err != nil {
+			t.Fatalf("Nth(%d) failed: %v", n, err)
+		}
+		if item.ClipText != want {
+			t.Errorf("Nth(%d): expected %q, got %q", n, want, item.ClipText)
+		}
+	}
+	if _, err := repo.Nth(4); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound past the end, got %v", err)
+	}
+	if _, err := repo.Nth(0); err == nil {
+		t.Error("expected error for position 0, got nil")
+	}
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TagList holds the tags of an item, scanned from the comma-separated list the item queries aggregate.
+type TagList []string
+
+// Scan implements sql.Scanner; tags are sorted, NULL means no tags.
+func (t *TagList) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("unexpected tag list of type %T", src)
+	}
+	tags := strings.Split(s, ",")
+	slices.Sort(tags)
+	*t = tags
+	return nil
+}
+
+// ValidateTag accepts tags of up to 64 characters without whitespace, control characters or commas.
+func ValidateTag(tag string) error {
+	if strings.Contains(tag, ",") {
+		return fmt.Errorf("tag %q contains a comma", tag)
+	}
+	return validateName("tag", tag)
+}
+
+func (r *Repository) migrateTags() error {
+	// the trigger drops the tags of deleted items, whichever way they are deleted (clean-up, clear, delete)
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS item_tags (
+			item_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (item_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags(tag);
+		CREATE TRIGGER IF NOT EXISTS trg_item_tags_cleanup AFTER DELETE ON clipboard_items
+		BEGIN
+			DELETE FROM item_tags WHERE item_id = OLD.id;
+		END
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create item_tags table: %w", err)
+	}
+	return nil
+}
+
+// TagItem adds tags to the record with the given id; tags are normalized like NormalizeTags.
+func (r *Repository) TagItem(id int, tags ...string) error {
+	tags = NormalizeTags(tags)
+	if err := validateTags(tags); err != nil {
+		return err
+	}
+	if err := r.ensureItem(id); err != nil {
+		return fmt.Errorf("failed to tag clipboard item (id=%d): %w", id, err)
+	}
+	return insertTags(r.db, id, tags)
+}
+
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertTags adds tags to the record with the given id through e (the database or a transaction).
+func insertTags(e sqlx.Execer, id int, tags []string) error {
+	for _, tag := range tags {
+		if _, err := e.Exec(`INSERT OR IGNORE INTO item_tags (item_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return fmt.Errorf("failed to tag clipboard item (id=%d) with %q: %w", id, tag, err)
+		}
+	}
+	return nil
+}
+
+// UntagItem removes tags from the record with the given id, all of them when tags is empty.
+func (r *Repository) UntagItem(id int, tags ...string) error {
+	if err := r.ensureItem(id); err != nil {
+		return fmt.Errorf("failed to untag clipboard item (id=%d): %w", id, err)
+	}
+	query, args := `DELETE FROM item_tags WHERE item_id = ?`, []any{id}
+	if tags = NormalizeTags(tags); len(tags) > 0 {
+		query += ` AND tag IN (?` + strings.Repeat(", ?", len(tags)-1) + `)`
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+	}
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to untag clipboard item (id=%d): %w", id, err)
+	}
+	return nil
+}
+
+// SetNote replaces the note of the record with the given id; an empty note removes it.
+// Notes are kept on one line, runs of whitespace become a single space.
+func (r *Repository) SetNote(id int, note string) error {
+	res, err := r.db.Exec(`UPDATE clipboard_items SET note = ? WHERE id = ?`, strings.Join(strings.Fields(note), " "), id)
+	if err != nil {
+		return fmt.Errorf("failed to set note of clipboard item (id=%d): %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to set note of clipboard item (id=%d): %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// ensureItem returns ErrNotFound unless the record with the given id exists.
+func (r *Repository) ensureItem(id int) error {
+	var exists bool
+	if err := r.db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM clipboard_items WHERE id = ?)`, id); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
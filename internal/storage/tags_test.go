@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTagItem(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 2)
+
+	if err := repo.TagItem(1, "Prod", "ticket-123,staging", "prod"); err != nil {
+		t.Fatalf("TagItem() failed: %v", err)
+	}
+	item, err := repo.Get(1)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if want := []string{"prod", "staging", "ticket-123"}; !slices.Equal(item.Tags, want) {
+		t.Errorf("expected tags %q, got %q", want, item.Tags)
+	}
+
+	if err = repo.UntagItem(1, "staging"); err != nil {
+		t.Fatalf("UntagItem() failed: %v", err)
+	}
+	if item, _ = repo.Get(1); !slices.Equal(item.Tags, []string{"prod", "ticket-123"}) {
+		t.Errorf("unexpected tags after untag: %q", item.Tags)
+	}
+	if err = repo.UntagItem(1); err != nil {
+		t.Fatalf("UntagItem() without tags failed: %v", err)
+	}
+	if item, _ = repo.Get(1); item.Tags != nil {
+		t.Errorf("expected no tags, got %q", item.Tags)
+	}
+
+	if err = repo.TagItem(99, "prod"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound tagging a missing item, got %v", err)
+	}
+	if err = repo.TagItem(1, "two words"); err == nil {
+		t.Error("expected error for a tag with whitespace")
+	}
+}
+
+func TestSetNote(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 1)
+
+	if err := repo.SetNote(1, "  staging\n login  "); err != nil {
+		t.Fatalf("SetNote() failed: %v", err)
+	}
+	if item, _ := repo.Get(1); item.Note != "staging login" {
+		t.Errorf("expected a one-line note, got %q", item.Note)
+	}
+	if err := repo.SetNote(1, ""); err != nil {
+		t.Fatalf("SetNote() clearing failed: %v", err)
+	}
+	if item, _ := repo.Get(1); item.Note != "" {
+		t.Errorf("expected no note, got %q", item.Note)
+	}
+	if err := repo.SetNote(99, "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing item, got %v", err)
+	}
+}
+
+func TestSearch_Tags(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 3)
+	if err := repo.TagItem(1, "prod", "db"); err != nil {
+		t.Fatalf("TagItem() failed: %v", err)
+	}
+	if err := repo.TagItem(3, "prod"); err != nil {
+		t.Fatalf("TagItem() failed: %v", err)
+	}
+
+	items, err := repo.Search(Filter{Tags: []string{"prod"}}, 0, 10)
+	if err != nil {
+		t.Fatalf("Search() failed: %v", err)
+	}
+	if got := texts(items); !slices.Equal(got, []string{"item-2", "item-0"}) {
+		t.Errorf("expected both prod items, got %q", got)
+	}
+	if items, _ = repo.Search(Filter{Tags: []string{"PROD", "db"}}, 0, 10); !slices.Equal(texts(items), []string{"item-0"}) {
+		t.Errorf("expected the item with all tags, got %q", texts(items))
+	}
+
+	if n, err := repo.DeleteMatching(Filter{Tags: []string{"prod"}}); err != nil || n != 2 {
+		t.Fatalf("DeleteMatching() = %d, %v, expected 2 deleted", n, err)
+	}
+	assertCount(t, repo, 1)
+}
+
+func TestWriteTagged(t *testing.T) {
+	repo := setupTestDB(t)
+
+	if err := repo.WriteTagged([]byte("kubectl get pods"), []string{"k8s"}); err != nil {
+		t.Fatalf("WriteTagged() failed: %v", err)
+	}
+	// a duplicate keeps its tags and gains the new ones
+	if err := repo.WriteTagged([]byte("kubectl get pods"), []string{"prod"}); err != nil {
+		t.Fatalf("WriteTagged() duplicate failed: %v", err)
+	}
+	items := mustRead(t, repo, 0, 10)
+	if len(items) != 1 || !slices.Equal(items[0].Tags, []string{"k8s", "prod"}) {
+		t.Errorf("expected one item tagged k8s and prod, got %+v", items)
+	}
+}
+
+func TestWriteTagged_InvalidTag(t *testing.T) {
+	repo := setupTestDB(t)
+
+	if err := repo.WriteTagged([]byte("kubectl get pods"), []string{"k8s", "prod cluster"}); err == nil {
+		t.Fatal("expected WriteTagged() to reject a tag with a space")
+	}
+	assertCount(t, repo, 0)
+}
+
+func TestTags_RemovedWithItem(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 2)
+	if err := repo.TagItem(1, "prod"); err != nil {
+		t.Fatalf("TagItem() failed: %v", err)
+	}
+
+	if err := repo.Delete(1); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	var n int
+	if err := repo.db.Get(&n, `SELECT COUNT(*) FROM item_tags`); err != nil {
+		t.Fatalf("failed to count tags: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected the tags of the deleted item to be gone, %d left", n)
+	}
+}
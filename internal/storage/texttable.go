@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// textTable is a table of texts kept apart from the history, like the paste queue and the clipboard stack:
+// a key column that orders the entries, the text and the time it was added.
+type textTable struct {
+	name    string // used in error messages
+	table   string
+	key     string
+	addedAt string
+	// next is the direction of the key that puts the next entry first (ASC for a queue, DESC for a stack)
+	next string
+}
+
+var (
+	queueTable = textTable{name: "paste queue", table: "paste_queue", key: "pos", addedAt: "queued_at", next: "ASC"}
+	stackTable = textTable{name: "stack", table: "clip_stack", key: "id", addedAt: "pushed_at", next: "DESC"}
+)
+
+func (t textTable) migrate(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + t.table + ` (
+			` + t.key + ` INTEGER PRIMARY KEY AUTOINCREMENT,
+			clip_text TEXT NOT NULL,
+			` + t.addedAt + ` DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", t.table, err)
+	}
+	return nil
+}
+
+// selectEntries returns the query for the entries of t, next one first.
+func (t textTable) selectEntries() string {
+	return `SELECT ` + t.key + `, clip_text, ` + t.addedAt + ` FROM ` + t.table + ` ORDER BY ` + t.key + ` ` + t.next
+}
+
+// add appends text to t through e (the database or a transaction).
+func (t textTable) add(e sqlx.Execer, text string, now time.Time) error {
+	if _, err := e.Exec(`INSERT INTO `+t.table+` (clip_text, `+t.addedAt+`) VALUES (?, ?)`, text, now); err != nil {
+		return fmt.Errorf("failed to add text (length=%d) to %s: %w", len(text), t.name, err)
+	}
+	return nil
+}
+
+// restore puts back an entry taken by takeEntry under its old key, hence at its old place.
+func (t textTable) restore(e sqlx.Execer, key int, text string, addedAt time.Time) error {
+	if _, err := e.Exec(`INSERT INTO `+t.table+` (`+t.key+`, clip_text, `+t.addedAt+`) VALUES (?, ?, ?)`,
+		key, text, addedAt); err != nil {
+		return fmt.Errorf("failed to restore %s entry (%s=%d): %w", t.name, t.key, key, err)
+	}
+	return nil
+}
+
+// count returns the number of entries in t.
+func (t textTable) count(db *sqlx.DB) (int, error) {
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM `+t.table); err != nil {
+		return 0, fmt.Errorf("failed to count %s entries: %w", t.name, err)
+	}
+	return n, nil
+}
+
+// clear empties t through e and returns how many entries were dropped.
+func (t textTable) clear(e sqlx.Execer) (int64, error) {
+	res, err := e.Exec(`DELETE FROM ` + t.table)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear %s: %w", t.name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cleared %s entries: %w", t.name, err)
+	}
+	return n, nil
+}
+
+// takeEntry removes the next entry of t and returns it; ErrNotFound when t is empty.
+// A single DELETE ... RETURNING claims the entry, so concurrent callers never get the same one.
+func takeEntry[E any](db *sqlx.DB, t textTable) (E, error) {
+	var e E
+	err := db.Get(&e, `DELETE FROM `+t.table+` WHERE `+t.key+` = (`+
+		`SELECT `+t.key+` FROM `+t.table+` ORDER BY `+t.key+` `+t.next+` LIMIT 1`+
+		`) RETURNING `+t.key+`, clip_text, `+t.addedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return e, fmt.Errorf("%s is empty: %w", t.name, ErrNotFound)
+	}
+	if err != nil {
+		return e, fmt.Errorf("failed to take the next %s entry: %w", t.name, err)
+	}
+	return e, nil
+}
+
+// entries returns the entries of t, next one first.
+func entries[E any](db *sqlx.DB, t textTable) ([]E, error) {
+	var es []E
+	if err := db.Select(&es, t.selectEntries()); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+	return es, nil
+}
@@ -0,0 +1,24 @@
+package storage
+
+import "testing"
+
+func TestTextTables_SurviveHistoryReset(t *testing.T) {
+	repo := setupTestDB(t)
+	seedItems(t, repo, 2)
+	if err := repo.ReplaceQueue([]string{"item-0"}); err != nil {
+		t.Fatalf("ReplaceQueue() failed: %v", err)
+	}
+	if _, err := repo.PushStack("item-1"); err != nil {
+		t.Fatalf("PushStack() failed: %v", err)
+	}
+	if err := repo.Reset(); err != nil {
+		t.Fatalf("Reset() failed: %v", err)
+	}
+
+	if es, err := repo.Queue(); err != nil || len(es) != 1 || es[0].Text != "item-0" {
+		t.Errorf("expected the paste queue to outlive the history, got %+v, %v", es, err)
+	}
+	if es, err := repo.Stack(); err != nil || len(es) != 1 || es[0].Text != "item-1" {
+		t.Errorf("expected the stack to outlive the history, got %+v, %v", es, err)
+	}
+}
@@ -0,0 +1,207 @@
+// Package transform rewrites the selected text before it is copied or pasted,
+// e.g. to decode a base64 token or to quote a string for the shell.
+package transform
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kaliv0/homie/internal/join"
+)
+
+// Transform is a named text rewrite.
+type Transform struct {
+	Name        string
+	Description string
+	apply       func(string) (string, error)
+}
+
+// Apply rewrites text.
+func (t Transform) Apply(text string) (string, error) {
+	out, err := t.apply(text)
+	if err != nil {
+		return "", fmt.Errorf("transform %q failed: %w", t.Name, err)
+	}
+	return out, nil
+}
+
+// ansiPattern matches CSI sequences (colors, cursor movement) and OSC sequences (titles, links).
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// builtins are always available; user transforms can't shadow them.
+var builtins = []Transform{
+	{Name: "shell-quote", Description: "quote as a single shell word", apply: plain(join.Quote)},
+	{Name: "json-escape", Description: "escape for use inside a JSON string", apply: jsonEscape},
+	{Name: "json-pretty", Description: "pretty-print JSON", apply: jsonPretty},
+	{Name: "url-encode", Description: "percent-encode for a URL query", apply: plain(url.QueryEscape)},
+	{Name: "url-decode", Description: "decode percent-encoding", apply: url.QueryUnescape},
+	{Name: "base64-encode", Description: "encode as base64", apply: base64Encode},
+	{Name: "base64-decode", Description: "decode base64 (standard or URL alphabet, padded or not)", apply: base64Decode},
+	{Name: "trim", Description: "remove leading and trailing whitespace", apply: plain(strings.TrimSpace)},
+	{Name: "upper", Description: "convert to upper case", apply: plain(strings.ToUpper)},
+	{Name: "lower", Description: "convert to lower case", apply: plain(strings.ToLower)},
+	{Name: "strip-ansi", Description: "remove terminal color and control sequences", apply: plain(func(s string) string {
+		return ansiPattern.ReplaceAllString(s, "")
+	})},
+}
+
+func plain(f func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		return f(s), nil
+	}
+}
+
+func jsonEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	// drop the surrounding quotes and the newline added by Encode
+	out := strings.TrimSuffix(buf.String(), "\n")
+	return out[1 : len(out)-1], nil
+}
+
+func jsonPretty(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(s)), "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func base64Encode(s string) (string, error) {
+	return base64.StdEncoding.EncodeToString([]byte(s)), nil
+}
+
+func base64Decode(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), "")
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		data, err := enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if !utf8.Valid(data) {
+			return "", errors.New("decoded data is not text")
+		}
+		return string(data), nil
+	}
+	return "", errors.New("invalid base64 input")
+}
+
+// command pipes the text through a shell command. As with editors,
+// the trailing newline most commands print is dropped unless the text ended with one.
+func command(cmdLine string) func(string) (string, error) {
+	return func(text string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", cmdLine)
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("command %q: %w: %s", cmdLine, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		out := stdout.String()
+		if !strings.HasSuffix(text, "\n") {
+			out = strings.TrimSuffix(out, "\n")
+		}
+		return out, nil
+	}
+}
+
+// Catalog holds the built-in and user-defined transforms.
+type Catalog struct {
+	transforms []Transform
+}
+
+// NewCatalog returns the built-ins followed by the user commands (name -> shell command), sorted by name.
+func NewCatalog(commands map[string]string) (*Catalog, error) {
+	c := &Catalog{transforms: slices.Clone(builtins)}
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, ok := c.lookup(name); ok {
+			return nil, fmt.Errorf("transform %q is built in and can't be redefined", name)
+		}
+		cmdLine := strings.TrimSpace(commands[name])
+		if cmdLine == "" {
+			return nil, fmt.Errorf("transform %q has no command", name)
+		}
+		c.transforms = append(c.transforms, Transform{Name: name, Description: "| " + cmdLine, apply: command(cmdLine)})
+	}
+	return c, nil
+}
+
+// All lists the transforms, built-ins first.
+func (c *Catalog) All() []Transform {
+	return slices.Clone(c.transforms)
+}
+
+func (c *Catalog) lookup(name string) (Transform, bool) {
+	i := slices.IndexFunc(c.transforms, func(t Transform) bool { return t.Name == name })
+	if i < 0 {
+		return Transform{}, false
+	}
+	return c.transforms[i], true
+}
+
+// Pipeline resolves names into a pipeline applied in the given order.
+func (c *Catalog) Pipeline(names []string) (Pipeline, error) {
+	p := make(Pipeline, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		t, ok := c.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q, choose between: %s", name, c.names())
+		}
+		p = append(p, t)
+	}
+	return p, nil
+}
+
+func (c *Catalog) names() string {
+	names := make([]string, 0, len(c.transforms))
+	for _, t := range c.transforms {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Pipeline is a sequence of transforms; the empty pipeline leaves text as it is.
+type Pipeline []Transform
+
+// Apply runs text through the transforms in order.
+func (p Pipeline) Apply(text string) (string, error) {
+	var err error
+	for _, t := range p {
+		if text, err = t.Apply(text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+func (p Pipeline) String() string {
+	names := make([]string, 0, len(p))
+	for _, t := range p {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ",")
+}
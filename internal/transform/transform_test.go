@@ -0,0 +1,123 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+)
+
+func catalog(t *testing.T, commands map[string]string) *Catalog {
+	t.Helper()
+	c, err := NewCatalog(commands)
+	if err != nil {
+		t.Fatalf("NewCatalog() failed: %v", err)
+	}
+	return c
+}
+
+func apply(t *testing.T, c *Catalog, names, text string) (string, error) {
+	t.Helper()
+	p, err := c.Pipeline(strings.Split(names, ","))
+	if err != nil {
+		t.Fatalf("Pipeline(%q) failed: %v", names, err)
+	}
+	return p.Apply(text)
+}
+
+func TestBuiltins(t *testing.T) {
+	c := catalog(t, nil)
+	tests := []struct {
+		names string
+		in    string
+		want  string
+	}{
+		{"shell-quote", "it's here", `'it'\''s here'`},
+		{"json-escape", "say \"hi\"\n<b>\t", `say \"hi\"\n<b>\t`},
+		{"json-pretty", ` {"a":[1,2],"b":{}} `, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"},
+		{"url-encode", "a b&c=d/é", "a+b%26c%3Dd%2F%C3%A9"},
+		{"url-decode", "a+b%26c%3Dd", "a b&c=d"},
+		{"base64-encode", "token:secret", "dG9rZW46c2VjcmV0"},
+		{"base64-decode", "dG9rZW46c2VjcmV0", "token:secret"},
+		{"base64-decode", "dG9rZW46c2VjcmV0Pz8_", "token:secret???"},
+		{"base64-decode", " aGk \n", "hi"},
+		{"trim", "  padded \n", "padded"},
+		{"upper", "MiXed", "MIXED"},
+		{"lower", "MiXed", "mixed"},
+		{"strip-ansi", "\x1b[1;31merror\x1b[0m: \x1b]8;;http://x\x1b\\link\x1b]8;;\x07", "error: link"},
+		{"trim,base64-decode,upper", " aGk= ", "HI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.names, func(t *testing.T) {
+			got, err := apply(t, c, tt.names, tt.in)
+			if err != nil {
+				t.Fatalf("Apply(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuiltins_Errors(t *testing.T) {
+	c := catalog(t, nil)
+	tests := map[string]string{
+		"base64-decode": "not base64!",
+		"json-pretty":   "{broken",
+		"url-decode":    "%zz",
+	}
+	for name, in := range tests {
+		if _, err := apply(t, c, name, in); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("%s(%q): expected an error naming the transform, got %v", name, in, err)
+		}
+	}
+	if _, err := apply(t, c, "base64-decode", "//79"); err == nil {
+		t.Error("expected an error for binary data")
+	}
+}
+
+func TestUserCommand(t *testing.T) {
+	c := catalog(t, map[string]string{"rev": "rev", "fail": "echo boom >&2; exit 3"})
+
+	got, err := apply(t, c, "rev", "abc")
+	if err != nil || got != "cba" {
+		t.Errorf("expected %q, got %q (err=%v)", "cba", got, err)
+	}
+	if got, _ = apply(t, c, "rev", "abc\n"); got != "cba\n" {
+		t.Errorf("expected the trailing newline to be kept, got %q", got)
+	}
+	if _, err = apply(t, c, "fail", "x"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the command's stderr in the error, got %v", err)
+	}
+}
+
+func TestNewCatalog(t *testing.T) {
+	if _, err := NewCatalog(map[string]string{"upper": "tr a-z A-Z"}); err == nil {
+		t.Error("expected error when redefining a built-in")
+	}
+	if _, err := NewCatalog(map[string]string{"noop": " "}); err == nil {
+		t.Error("expected error for an empty command")
+	}
+
+	all := catalog(t, map[string]string{"zz": "cat", "aa": "cat"}).All()
+	if n := len(all); n != len(builtins)+2 || all[n-2].Name != "aa" || all[n-1].Name != "zz" {
+		t.Errorf("expected built-ins followed by sorted user transforms, got %d transforms", n)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	c := catalog(t, nil)
+	if _, err := c.Pipeline([]string{"upper", "nope"}); err == nil || !strings.Contains(err.Error(), "shell-quote") {
+		t.Errorf("expected unknown transform error listing the choices, got %v", err)
+	}
+
+	p, err := c.Pipeline([]string{" trim", "", "upper "})
+	if err != nil {
+		t.Fatalf("Pipeline() failed: %v", err)
+	}
+	if p.String() != "trim,upper" {
+		t.Errorf("unexpected pipeline %q", p)
+	}
+	if got, _ := Pipeline(nil).Apply("same"); got != "same" {
+		t.Errorf("expected the empty pipeline to keep the text, got %q", got)
+	}
+}